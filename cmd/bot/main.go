@@ -4,9 +4,16 @@ import (
 	"context"
 	"log"
 
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/alert"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/audit"
 	"github.com/HarkushaVlad/docker-monitor-bot/internal/bot"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/callback"
 	"github.com/HarkushaVlad/docker-monitor-bot/internal/config"
 	"github.com/HarkushaVlad/docker-monitor-bot/internal/docker"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/incident"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/rules"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/silence"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/totp"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -16,22 +23,58 @@ func main() {
 		log.Fatalf("Error loading configuration: %v", err)
 	}
 
-	if err := bot.InitTelegramBot(); err != nil {
+	if err := bot.InitTelegramBot(cfg); err != nil {
 		log.Fatalf("Failed to initialize Telegram bot: %v", err)
 	}
 
 	notifier := &bot.TelegramNotifier{Bot: bot.TelegramBot}
 
+	callback.Init(cfg.CallbackSecret, cfg.CallbackTTL)
+
+	if err := silence.Init(cfg.StateDir); err != nil {
+		log.Fatalf("Failed to load silences: %v", err)
+	}
+
+	if err := incident.Init(cfg.StateDir); err != nil {
+		log.Fatalf("Failed to load incidents: %v", err)
+	}
+
+	if err := audit.Init(cfg.StateDir); err != nil {
+		log.Fatalf("Failed to initialize audit log: %v", err)
+	}
+
+	if err := totp.Init(cfg.StateDir); err != nil {
+		log.Fatalf("Failed to load two-factor enrollments: %v", err)
+	}
+
+	ruleEngine, err := rules.Load(cfg.RulesFile, cfg.StderrIsError)
+	if err != nil {
+		log.Fatalf("Failed to load log matching rules: %v", err)
+	}
+
 	if err := docker.InitDockerClient(); err != nil {
 		log.Fatalf("Failed to initialize Docker client: %v", err)
 	}
 
+	if err := docker.InitExecCommands(cfg.ExecCommandsFile); err != nil {
+		log.Fatalf("Failed to load exec commands: %v", err)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go docker.MonitorDockerEvents(ctx, cfg.TelegramChatID, notifier)
+	go incident.Run(ctx)
+
+	go docker.MonitorDockerEvents(ctx, cfg, notifier)
+
+	go alert.RunEscalation(ctx, notifier, alert.EscalationPolicy{
+		PrimaryChatID:   cfg.TelegramChatID,
+		SecondaryChatID: cfg.EscalationChatID,
+		Interval:        cfg.EscalationInterval,
+		MaxResends:      cfg.EscalationMaxResends,
+	})
 
-	go docker.MonitorContainerLogs(ctx, cfg.PollInterval, cfg.TailCount, cfg.TelegramChatID, notifier)
+	go docker.MonitorContainerLogs(ctx, cfg, ruleEngine, notifier)
 
 	go func() {
 		u := tgbotapi.NewUpdate(0)
@@ -42,8 +85,10 @@ func main() {
 			if update.CallbackQuery != nil {
 				bot.HandleCallbackQuery(bot.TelegramBot, update.CallbackQuery, notifier)
 			}
-			if update.Message != nil && update.Message.IsCommand() && update.Message.Chat.ID == cfg.TelegramChatID {
+			if update.Message != nil && update.Message.IsCommand() {
 				bot.HandleCommand(bot.TelegramBot, update.Message, notifier)
+			} else if update.Message != nil {
+				bot.HandleMessage(bot.TelegramBot, update.Message, notifier)
 			}
 		}
 	}()
@@ -0,0 +1,107 @@
+package alert
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/callback"
+)
+
+const (
+	CallbackPrefix = "alert_"
+
+	ActionAll      = "all"
+	ActionContext  = "ctx"
+	ActionDownload = "dl"
+	ActionOpen     = "open"
+
+	MuteCallbackPrefix = "mute_"
+
+	maxStoredAlerts = 500
+)
+
+type Entry struct {
+	Text      string
+	LineIndex int
+	LineCount int
+}
+
+type ErrorAlert struct {
+	ID            string
+	ContainerID   string
+	ContainerName string
+	CreatedAt     time.Time
+	Entries       []Entry
+	Window        []string
+	WindowEnd     time.Time
+}
+
+type store struct {
+	mu     sync.Mutex
+	nextID uint32
+	alerts map[string]*ErrorAlert
+	order  []string
+}
+
+var alerts = &store{nextID: uint32(time.Now().Unix()), alerts: make(map[string]*ErrorAlert)}
+
+func Add(a *ErrorAlert) string {
+	alerts.mu.Lock()
+	defer alerts.mu.Unlock()
+
+	alerts.nextID++
+	a.ID = strconv.FormatUint(uint64(alerts.nextID), 10)
+	alerts.alerts[a.ID] = a
+	alerts.order = append(alerts.order, a.ID)
+	if len(alerts.order) > maxStoredAlerts {
+		delete(alerts.alerts, alerts.order[0])
+		alerts.order = alerts.order[1:]
+	}
+	return a.ID
+}
+
+func Get(id string) (*ErrorAlert, bool) {
+	alerts.mu.Lock()
+	defer alerts.mu.Unlock()
+
+	a, ok := alerts.alerts[id]
+	return a, ok
+}
+
+func CallbackData(action, id, containerID string) string {
+	numericID, _ := strconv.ParseUint(id, 10, 32)
+	return callback.NewWithArg(CallbackPrefix+action, containerID, uint32(numericID), 0)
+}
+
+func MuteCallbackData(duration, containerID string) string {
+	return callback.New(MuteCallbackPrefix+duration, containerID, 0, 0)
+}
+
+func MuteRow(containerID string) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔕 1h", MuteCallbackData("1h", containerID)),
+		tgbotapi.NewInlineKeyboardButtonData("🔕 24h", MuteCallbackData("24h", containerID)),
+		tgbotapi.NewInlineKeyboardButtonData("🔕 Forever", MuteCallbackData("forever", containerID)),
+	)
+}
+
+func EventKeyboard(containerID string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(MuteRow(containerID))
+}
+
+func ErrorKeyboard(id, containerID string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📋 All errors", CallbackData(ActionAll, id, containerID)),
+			tgbotapi.NewInlineKeyboardButtonData("🔍 Context", CallbackData(ActionContext, id, containerID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💾 Download", CallbackData(ActionDownload, id, containerID)),
+			tgbotapi.NewInlineKeyboardButtonData("📦 Container", CallbackData(ActionOpen, id, containerID)),
+		),
+		MuteRow(containerID),
+	)
+}
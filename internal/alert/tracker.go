@@ -0,0 +1,253 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/callback"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const (
+	AckAction         = "ack"
+	AckCallbackPrefix = AckAction + "_"
+
+	trackedRetention      = 24 * time.Hour
+	escalationCheckPeriod = 30 * time.Second
+
+	messageLimit = 4096
+	captionLimit = 1024
+
+	escalatedPrefix = "🚨 <b>Escalated:</b> "
+)
+
+var htmlTagRe = regexp.MustCompile(`<[^>]*>`)
+
+type MessageRef struct {
+	ChatID     int64
+	MessageID  int
+	IsDocument bool
+}
+
+type Tracked struct {
+	ID          string
+	ContainerID string
+	Text        string
+	Keyboard    tgbotapi.InlineKeyboardMarkup
+	Critical    bool
+	Messages    []MessageRef
+	SentAt      time.Time
+	LastSentAt  time.Time
+	Resends     int
+	Escalated   bool
+	AckedBy     string
+	AckedAt     time.Time
+	ResolvedAt  time.Time
+}
+
+type EscalationPolicy struct {
+	PrimaryChatID   int64
+	SecondaryChatID int64
+	Interval        time.Duration
+	MaxResends      int
+}
+
+type tracker struct {
+	mu      sync.Mutex
+	nextID  uint32
+	tracked map[string]*Tracked
+}
+
+var alertTracker = &tracker{nextID: uint32(time.Now().Unix()), tracked: make(map[string]*Tracked)}
+
+func newTracked(containerID, text string, keyboard tgbotapi.InlineKeyboardMarkup, critical bool) *Tracked {
+	alertTracker.mu.Lock()
+	defer alertTracker.mu.Unlock()
+
+	alertTracker.nextID++
+	t := &Tracked{
+		ID:          strconv.FormatUint(uint64(alertTracker.nextID), 10),
+		ContainerID: containerID,
+		Text:        text,
+		Keyboard:    keyboard,
+		Critical:    critical,
+		SentAt:      time.Now(),
+		LastSentAt:  time.Now(),
+	}
+	alertTracker.tracked[t.ID] = t
+	return t
+}
+
+func (t *Tracked) keyboardWithAck() tgbotapi.InlineKeyboardMarkup {
+	id, _ := strconv.ParseUint(t.ID, 10, 32)
+	rows := append([][]tgbotapi.InlineKeyboardButton{}, t.Keyboard.InlineKeyboard...)
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Acknowledge", callback.NewWithArg(AckAction, t.ContainerID, uint32(id), 0)),
+	))
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func Send(notifier notification.Notifier, chatID int64, containerID, text string, keyboard tgbotapi.InlineKeyboardMarkup, critical bool) *Tracked {
+	t := newTracked(containerID, text, keyboard, critical)
+	t.addMessage(chatID, notifier.SendTextWithKeyboard(chatID, text, t.keyboardWithAck()), false)
+	return t
+}
+
+func SendDocument(notifier notification.Notifier, chatID int64, containerID, fileName string, data []byte, caption string, keyboard tgbotapi.InlineKeyboardMarkup, critical bool) *Tracked {
+	t := newTracked(containerID, caption, keyboard, critical)
+	t.addMessage(chatID, notifier.SendDocumentWithKeyboard(chatID, fileName, data, caption, t.keyboardWithAck()), true)
+	return t
+}
+
+func (t *Tracked) addMessage(chatID int64, messageID int, isDocument bool) {
+	if messageID == 0 {
+		return
+	}
+	alertTracker.mu.Lock()
+	defer alertTracker.mu.Unlock()
+	t.Messages = append(t.Messages, MessageRef{ChatID: chatID, MessageID: messageID, IsDocument: isDocument})
+}
+
+func GetTracked(id string) (*Tracked, bool) {
+	alertTracker.mu.Lock()
+	defer alertTracker.mu.Unlock()
+
+	t, ok := alertTracker.tracked[id]
+	return t, ok
+}
+
+func Acknowledge(notifier notification.Notifier, id, by string) (*Tracked, bool) {
+	alertTracker.mu.Lock()
+	t, ok := alertTracker.tracked[id]
+	if !ok || !t.AckedAt.IsZero() {
+		alertTracker.mu.Unlock()
+		return t, false
+	}
+	t.AckedBy = by
+	t.AckedAt = time.Now()
+	t.Text = withNote(t.Text, fmt.Sprintf("\n\n✅ <i>Acknowledged by %s at %s</i>", utils.EscapeHTML(by), t.AckedAt.Format("2006-01-02 15:04:05")), t.limitLocked())
+	alertTracker.mu.Unlock()
+
+	t.render(notifier, t.Keyboard)
+	return t, true
+}
+
+func Resolve(notifier notification.Notifier, t *Tracked, downtime time.Duration) {
+	alertTracker.mu.Lock()
+	if !t.ResolvedAt.IsZero() {
+		alertTracker.mu.Unlock()
+		return
+	}
+	t.ResolvedAt = time.Now()
+	t.Text = withNote(t.Text, fmt.Sprintf("\n\n✅ <b>Resolved after %s</b>", downtime.Round(time.Second)), t.limitLocked())
+	alertTracker.mu.Unlock()
+
+	t.render(notifier, t.Keyboard)
+}
+
+func (t *Tracked) limitLocked() int {
+	for _, ref := range t.Messages {
+		if ref.IsDocument {
+			return captionLimit
+		}
+	}
+	return messageLimit
+}
+
+func withNote(text, note string, limit int) string {
+	if utf8.RuneCountInString(text+note) <= limit {
+		return text + note
+	}
+	plain := html.UnescapeString(htmlTagRe.ReplaceAllString(text, ""))
+	return utils.EscapeHTML(utils.Truncate(plain, limit-utf8.RuneCountInString(note)-1)) + note
+}
+
+func (t *Tracked) render(notifier notification.Notifier, keyboard tgbotapi.InlineKeyboardMarkup) {
+	alertTracker.mu.Lock()
+	text := t.Text
+	messages := append([]MessageRef(nil), t.Messages...)
+	alertTracker.mu.Unlock()
+
+	for _, ref := range messages {
+		if ref.IsDocument {
+			notifier.EditMessageCaptionWithKeyboard(ref.ChatID, ref.MessageID, text, keyboard)
+		} else {
+			notifier.EditMessageWithKeyboard(ref.ChatID, ref.MessageID, text, keyboard)
+		}
+	}
+}
+
+func RunEscalation(ctx context.Context, notifier notification.Notifier, policy EscalationPolicy) {
+	ticker := time.NewTicker(escalationCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			for _, t := range dueForEscalation(now, policy) {
+				escalate(notifier, t, policy)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func dueForEscalation(now time.Time, policy EscalationPolicy) []*Tracked {
+	alertTracker.mu.Lock()
+	defer alertTracker.mu.Unlock()
+
+	var due []*Tracked
+	for id, t := range alertTracker.tracked {
+		if now.Sub(t.SentAt) > trackedRetention {
+			delete(alertTracker.tracked, id)
+			continue
+		}
+		if policy.Interval <= 0 || !t.Critical || !t.AckedAt.IsZero() || !t.ResolvedAt.IsZero() || t.Resends >= policy.MaxResends {
+			continue
+		}
+		if now.Sub(t.LastSentAt) >= policy.Interval {
+			t.Resends++
+			t.LastSentAt = now
+			due = append(due, t)
+		}
+	}
+	return due
+}
+
+func escalate(notifier notification.Notifier, t *Tracked, policy EscalationPolicy) {
+	alertTracker.mu.Lock()
+	text := t.Text
+	resends := t.Resends
+	escalateNow := policy.SecondaryChatID != 0 && !t.Escalated
+	if escalateNow {
+		t.Escalated = true
+	}
+	alertTracker.mu.Unlock()
+
+	header := fmt.Sprintf(
+		"🔔 <b>Unacknowledged critical alert</b> (reminder %d/%d, first sent %s ago)\n\n",
+		resends,
+		policy.MaxResends,
+		time.Since(t.SentAt).Round(time.Minute),
+	)
+	reminder := header + withNote(text, "", messageLimit-utf8.RuneCountInString(header+escalatedPrefix))
+
+	log.Printf("Re-sending unacknowledged alert %s (reminder %d)", t.ID, resends)
+	t.addMessage(policy.PrimaryChatID, notifier.SendTextWithKeyboard(policy.PrimaryChatID, reminder, t.keyboardWithAck()), false)
+	if escalateNow {
+		log.Printf("Escalating alert %s to chat %d", t.ID, policy.SecondaryChatID)
+		escalated := escalatedPrefix + reminder
+		t.addMessage(policy.SecondaryChatID, notifier.SendTextWithKeyboard(policy.SecondaryChatID, escalated, t.keyboardWithAck()), false)
+	}
+}
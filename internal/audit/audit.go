@@ -0,0 +1,142 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const auditFileName = "audit.jsonl"
+
+const (
+	ResultOK     = "ok"
+	ResultFailed = "failed"
+)
+
+type Entry struct {
+	Time        time.Time `json:"time"`
+	UserID      int64     `json:"user_id"`
+	User        string    `json:"user"`
+	ChatID      int64     `json:"chat_id"`
+	Action      string    `json:"action"`
+	ContainerID string    `json:"container_id,omitempty"`
+	Container   string    `json:"container,omitempty"`
+	Result      string    `json:"result"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+}
+
+func (e Entry) Duration() time.Duration {
+	return time.Duration(e.DurationMs) * time.Millisecond
+}
+
+func (e Entry) matches(filter string) bool {
+	if filter == "" {
+		return true
+	}
+	if strings.HasPrefix(filter, "@") || isNumeric(filter) {
+		return strings.EqualFold(e.User, filter) || strconv.FormatInt(e.UserID, 10) == filter
+	}
+	return strings.EqualFold(e.Container, filter) || strings.HasPrefix(e.ContainerID, filter)
+}
+
+var (
+	mu       sync.Mutex
+	filePath string
+)
+
+func Init(stateDir string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := os.MkdirAll(stateDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %v", err)
+	}
+	filePath = filepath.Join(stateDir, auditFileName)
+	return nil
+}
+
+func Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if filePath == "" {
+		return nil
+	}
+	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", filePath, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %v", filePath, err)
+	}
+	return nil
+}
+
+func Recent(filter string, offset, limit int) ([]Entry, bool, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if filePath == "" {
+		return nil, false, nil
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to open %s: %v", filePath, err)
+	}
+	defer f.Close()
+
+	var matched []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if entry.matches(filter) {
+			matched = append(matched, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %v", filePath, err)
+	}
+
+	end := len(matched) - offset
+	if end <= 0 {
+		return nil, false, nil
+	}
+	start := end - limit
+	if start < 0 {
+		start = 0
+	}
+	page := make([]Entry, 0, end-start)
+	for i := end - 1; i >= start; i-- {
+		page = append(page, matched[i])
+	}
+	return page, start > 0, nil
+}
+
+func isNumeric(value string) bool {
+	_, err := strconv.ParseInt(value, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleViewer
+	RoleOperator
+	RoleAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleOperator:
+		return "operator"
+	case RoleAdmin:
+		return "admin"
+	}
+	return "none"
+}
+
+func ParseRole(value string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "viewer":
+		return RoleViewer, nil
+	case "operator":
+		return RoleOperator, nil
+	case "admin":
+		return RoleAdmin, nil
+	}
+	return RoleNone, fmt.Errorf("unknown role %q, expected viewer, operator or admin", value)
+}
+
+type Policy struct {
+	chats map[int64]Role
+	users map[int64]Role
+}
+
+func NewPolicy(chats, users map[int64]string) (*Policy, error) {
+	p := &Policy{chats: make(map[int64]Role), users: make(map[int64]Role)}
+	for id, name := range chats {
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("chat %d: %v", id, err)
+		}
+		p.chats[id] = role
+	}
+	for id, name := range users {
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("user %d: %v", id, err)
+		}
+		p.users[id] = role
+	}
+	return p, nil
+}
+
+func (p *Policy) RoleOf(chatID, userID int64) Role {
+	if role, ok := p.users[userID]; ok {
+		return role
+	}
+	return p.chats[chatID]
+}
+
+func (p *Policy) Admins() []int64 {
+	var admins []int64
+	for id, role := range p.users {
+		if role == RoleAdmin {
+			admins = append(admins, id)
+		}
+	}
+	sort.Slice(admins, func(i, j int) bool { return admins[i] < admins[j] })
+	return admins
+}
@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/alert"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/auth"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const deniedReportInterval = time.Minute
+
+var commandRoles = map[string]auth.Role{
+	"check":     auth.RoleViewer,
+	"list":      auth.RoleViewer,
+	"incidents": auth.RoleViewer,
+	"silences":  auth.RoleViewer,
+	"mute":      auth.RoleOperator,
+	"unmute":    auth.RoleOperator,
+	"audit":     auth.RoleAdmin,
+	"2fa":       auth.RoleOperator,
+	"exec":      auth.RoleOperator,
+	"logs":      auth.RoleViewer,
+}
+
+var callbackRoles = []struct {
+	prefix string
+	role   auth.Role
+}{
+	{"container_", auth.RoleViewer},
+	{"page_", auth.RoleViewer},
+	{incidentCallbackPrefix, auth.RoleViewer},
+	{incidentsPageCallbackPrefix, auth.RoleViewer},
+	{alert.CallbackPrefix, auth.RoleViewer},
+	{logsCallbackPrefix, auth.RoleViewer},
+	{"action_remove", auth.RoleAdmin},
+	{"action_", auth.RoleOperator},
+	{execCallbackPrefix, auth.RoleOperator},
+	{confirmCallbackPrefix, auth.RoleOperator},
+	{cancelCallbackPrefix, auth.RoleOperator},
+	{alert.MuteCallbackPrefix, auth.RoleOperator},
+	{alert.AckCallbackPrefix, auth.RoleOperator},
+	{unmuteCallbackPrefix, auth.RoleOperator},
+	{auditCallbackPrefix, auth.RoleAdmin},
+}
+
+var (
+	accessPolicy *auth.Policy
+
+	lastDeniedReport = make(map[int64]time.Time)
+	deniedReportMux  = &sync.Mutex{}
+)
+
+func callbackRole(data string) auth.Role {
+	for _, entry := range callbackRoles {
+		if strings.HasPrefix(data, entry.prefix) {
+			return entry.role
+		}
+	}
+	return auth.RoleAdmin
+}
+
+func authorize(chatID int64, user *tgbotapi.User, required auth.Role) bool {
+	var userID int64
+	if user != nil {
+		userID = user.ID
+	}
+	return accessPolicy.RoleOf(chatID, userID) >= required
+}
+
+func reportDenied(chatID int64, user *tgbotapi.User, attempt string, required auth.Role, notifier notification.Notifier) {
+	var userID int64
+	if user != nil {
+		userID = user.ID
+	}
+	role := accessPolicy.RoleOf(chatID, userID)
+	log.Printf("Access denied: user=%d (%s) chat=%d role=%s required=%s attempt=%q", userID, userName(user), chatID, role, required, attempt)
+
+	deniedReportMux.Lock()
+	last := lastDeniedReport[userID]
+	throttled := time.Since(last) < deniedReportInterval
+	if !throttled {
+		lastDeniedReport[userID] = time.Now()
+	}
+	deniedReportMux.Unlock()
+	if throttled {
+		return
+	}
+
+	message := fmt.Sprintf(
+		"⛔ <b>Access denied</b>\n\n"+
+			"<pre>"+
+			"┌ User: %s (%d)\n"+
+			"├ Chat: %d\n"+
+			"├ Role: %s, required: %s\n"+
+			"└ Attempt: %s"+
+			"</pre>",
+		utils.EscapeHTML(userName(user)),
+		userID,
+		chatID,
+		role,
+		required,
+		utils.EscapeHTML(utils.Truncate(attempt, 200)),
+	)
+	notifyAdmins(chatID, message, notifier)
+}
+
+func notifyAdmins(chatID int64, message string, notifier notification.Notifier) {
+	admins := accessPolicy.Admins()
+	if len(admins) == 0 {
+		admins = []int64{botConfig.TelegramChatID}
+	}
+	for _, admin := range admins {
+		if admin != chatID {
+			notifier.SendText(admin, message)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/callback"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/docker"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+func baseAction(actionType string) string {
+	return strings.SplitN(actionType, "_", 2)[0]
+}
+
+func actionLabel(actionType string) string {
+	switch {
+	case strings.HasPrefix(actionType, "kill_"):
+		return "kill with " + strings.TrimPrefix(actionType, "kill_")
+	case actionType == "remove_container":
+		return "remove"
+	case actionType == "remove_volumes":
+		return "remove with its volumes"
+	}
+	return actionType
+}
+
+func containerActionsKeyboard(chatID int64, container types.ContainerJSON, state *BotState) tgbotapi.InlineKeyboardMarkup {
+	button := func(text, action string) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(text, callback.New(action, container.ID, state.CurrentPage, chatID))
+	}
+
+	var buttons []tgbotapi.InlineKeyboardButton
+	switch {
+	case container.State.Paused:
+		buttons = append(buttons, button("⏯️ Unpause", "unpause"), button("⏹️ Stop", "stop"), button("💀 Kill", "kill"))
+	case container.State.Running:
+		buttons = append(buttons,
+			button("⏹️ Stop", "stop"),
+			button("🔄 Restart", "restart"),
+			button("⏸️ Pause", "pause"),
+			button("💀 Kill", "kill"),
+		)
+	default:
+		buttons = append(buttons, button("▶️ Start", "start"), button("🗑️ Remove", "remove"))
+	}
+	buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("↩️ Back", callback.New("page", "", state.CurrentPage, chatID)))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(buttons); i += 2 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(buttons[i:utils.Min(i+2, len(buttons))]...))
+	}
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func showKillOptions(chatID int64, messageID int, fullID string, notifier notification.Notifier, state *BotState) {
+	container, err := docker.DockerClient.ContainerInspect(context.Background(), fullID)
+	if err != nil {
+		editOrSendErrorMessage(chatID, messageID, "Container not found", notifier)
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(docker.KillSignals); i += 2 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, signal := range docker.KillSignals[i:utils.Min(i+2, len(docker.KillSignals))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(signal, callback.New("kill_"+signal, fullID, state.CurrentPage, chatID)))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("↩️ Back", callback.New("open", fullID, state.CurrentPage, chatID)),
+	))
+
+	text := fmt.Sprintf("💀 Select the signal to send to <u><b>%s</b></u>:", utils.EscapeHTML(strings.TrimPrefix(container.Name, "/")))
+	notifier.EditMessageWithKeyboard(chatID, messageID, text, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows})
+}
+
+func showRemoveOptions(chatID int64, messageID int, fullID string, notifier notification.Notifier, state *BotState) {
+	container, err := docker.DockerClient.ContainerInspect(context.Background(), fullID)
+	if err != nil {
+		editOrSendErrorMessage(chatID, messageID, "Container not found", notifier)
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑️ Container only", callback.New("remove_container", fullID, state.CurrentPage, chatID)),
+			tgbotapi.NewInlineKeyboardButtonData("🗑️ With volumes", callback.New("remove_volumes", fullID, state.CurrentPage, chatID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Back", callback.New("open", fullID, state.CurrentPage, chatID)),
+		),
+	)
+
+	text := fmt.Sprintf("🗑️ Remove <u><b>%s</b></u>?\n\nAnonymous volumes are only deleted when removing with volumes.", utils.EscapeHTML(strings.TrimPrefix(container.Name, "/")))
+	notifier.EditMessageWithKeyboard(chatID, messageID, text, keyboard)
+}
@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/alert"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/docker"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const telegramMessageLimit = 4096
+
+func handleAlertAction(chatID int64, data string, notifier notification.Notifier, state *BotState) {
+	parts := strings.Split(strings.TrimPrefix(data, alert.CallbackPrefix), "_")
+	if len(parts) != 3 {
+		return
+	}
+	action, alertID, shortID := parts[0], parts[1], parts[2]
+	if action == alert.ActionOpen {
+		showContainerDetails(chatID, 0, shortID, notifier, state)
+		return
+	}
+
+	errorAlert, ok := alert.Get(alertID)
+	if !ok {
+		name := shortID
+		if fullID, exists := state.ShortIDMap[shortID]; exists {
+			if container, err := docker.DockerClient.ContainerInspect(context.Background(), fullID); err == nil {
+				name = strings.TrimPrefix(container.Name, "/")
+			}
+		}
+		notifier.SendText(chatID, fmt.Sprintf(
+			"⌛ The details of this alert are no longer kept, the bot has restarted or it is too old.\nUse <code>/logs %s</code> to see the recent logs.",
+			utils.EscapeHTML(name),
+		))
+		return
+	}
+
+	fileBase := fmt.Sprintf("%s-%s", errorAlert.ContainerName, errorAlert.CreatedAt.Format("20060102-150405"))
+
+	switch action {
+	case alert.ActionAll:
+		var entries []string
+		for _, entry := range errorAlert.Entries {
+			entries = append(entries, entry.Text)
+		}
+		title := fmt.Sprintf("📋 All %d errors of <u>%s</u>", len(entries), errorAlert.ContainerName)
+		sendTextOrDocument(chatID, title, entries, fileBase+"-errors.log", notifier)
+	case alert.ActionContext:
+		go sendAlertContext(chatID, errorAlert, fileBase, notifier)
+	case alert.ActionDownload:
+		caption := fmt.Sprintf("💾 Log window of <u>%s</u> (%d lines)", errorAlert.ContainerName, len(errorAlert.Window))
+		notifier.SendDocument(chatID, fileBase+".log", []byte(strings.Join(errorAlert.Window, "\n")), caption)
+	}
+}
+
+func sendAlertContext(chatID int64, errorAlert *alert.ErrorAlert, fileBase string, notifier notification.Notifier) {
+	contextLines := botConfig.AlertContextLines
+	window := append([]string(nil), errorAlert.Window...)
+	missing := 0
+	for _, entry := range errorAlert.Entries {
+		if end := entry.LineIndex + entry.LineCount + contextLines; end-len(window) > missing {
+			missing = end - len(window)
+		}
+	}
+	if missing > 0 && !errorAlert.WindowEnd.IsZero() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		following, err := docker.LogLinesAfter(ctx, errorAlert.ContainerID, errorAlert.WindowEnd, missing)
+		cancel()
+		if err != nil {
+			log.Printf("Error fetching log context of container %s: %v", errorAlert.ContainerName, err)
+		}
+		for _, line := range following {
+			window = append(window, fmt.Sprintf("[%s] %s", line.Stream, line.Text))
+		}
+	}
+
+	var blocks []string
+	for _, entry := range errorAlert.Entries {
+		start := entry.LineIndex - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := entry.LineIndex + entry.LineCount + contextLines
+		if end > len(window) {
+			end = len(window)
+		}
+		blocks = append(blocks, strings.Join(window[start:end], "\n"))
+	}
+	title := fmt.Sprintf("🔍 Context of errors in <u>%s</u> (±%d lines)", errorAlert.ContainerName, contextLines)
+	sendTextOrDocument(chatID, title, blocks, fileBase+"-context.log", notifier)
+}
+
+func sendTextOrDocument(chatID int64, title string, blocks []string, fileName string, notifier notification.Notifier) {
+	var escaped []string
+	for _, block := range blocks {
+		filtered := utils.RemoveControlCharactersRegex(strings.ToValidUTF8(block, ""))
+		escaped = append(escaped, fmt.Sprintf("<pre>%s</pre>", utils.EscapeHTML(filtered)))
+	}
+
+	message := fmt.Sprintf("<b>%s</b>\n\n%s", title, strings.Join(escaped, "\n"))
+	if utf8.RuneCountInString(message) <= telegramMessageLimit {
+		notifier.SendText(chatID, message)
+		return
+	}
+	notifier.SendDocument(chatID, fileName, []byte(strings.Join(blocks, "\n\n")), "<b>"+title+"</b>")
+}
+
+func handleAckCallback(chatID int64, data string, from *tgbotapi.User, notifier notification.Notifier) {
+	tracked, ok := alert.Acknowledge(notifier, strings.TrimPrefix(data, alert.AckCallbackPrefix), userName(from))
+	if ok {
+		return
+	}
+	if tracked == nil {
+		notifier.SendText(chatID, "⌛ This alert is no longer tracked, it was resolved or the bot has restarted since it was sent")
+		return
+	}
+	notifier.SendText(chatID, fmt.Sprintf("ℹ️ Alert was already acknowledged by %s at %s", utils.EscapeHTML(tracked.AckedBy), tracked.AckedAt.Format("2006-01-02 15:04:05")))
+}
@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/audit"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const (
+	auditCallbackPrefix = "audit_"
+	auditPageSize       = 15
+)
+
+func handleAuditCommand(chatID int64, msg *tgbotapi.Message, notifier notification.Notifier) {
+	showAuditPage(chatID, 0, strings.TrimSpace(msg.CommandArguments()), 0, notifier)
+}
+
+func handleAuditCallback(chatID int64, messageID int, data string, notifier notification.Notifier) {
+	parts := strings.SplitN(strings.TrimPrefix(data, auditCallbackPrefix), "_", 2)
+	offset, err := strconv.Atoi(parts[0])
+	if err != nil || offset < 0 {
+		return
+	}
+	filter := ""
+	if len(parts) == 2 {
+		filter = parts[1]
+	}
+	showAuditPage(chatID, messageID, filter, offset, notifier)
+}
+
+func showAuditPage(chatID int64, messageID int, filter string, offset int, notifier notification.Notifier) {
+	entries, more, err := audit.Recent(filter, offset, auditPageSize)
+	if err != nil {
+		editOrSendErrorMessage(chatID, messageID, "Failed to read audit log: "+utils.EscapeHTML(err.Error()), notifier)
+		return
+	}
+
+	title := "📜 <b>Audit log</b>"
+	if filter != "" {
+		title = fmt.Sprintf("📜 <b>Audit log for %s</b>", utils.EscapeHTML(utils.Truncate(filter, 100)))
+	}
+	if len(entries) == 0 {
+		editOrSendMessage(chatID, messageID, title+"\n\nNo entries.", notifier)
+		return
+	}
+
+	text := title + "\n"
+	shown := 0
+	for _, entry := range entries {
+		line := "\n" + formatAuditEntry(entry)
+		if shown > 0 && utf8.RuneCountInString(text)+utf8.RuneCountInString(line) > telegramMessageLimit {
+			break
+		}
+		text += line
+		shown++
+	}
+	more = more || shown < len(entries)
+
+	var paginationRow []tgbotapi.InlineKeyboardButton
+	if offset > 0 {
+		newer := offset - auditPageSize
+		if newer < 0 {
+			newer = 0
+		}
+		paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData("⬅ Newer", auditCallbackData(newer, filter)))
+	}
+	if more {
+		paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData("Older ➡", auditCallbackData(offset+shown, filter)))
+	}
+	if len(paginationRow) == 0 || len(auditCallbackData(offset+shown, filter)) > 64 {
+		editOrSendMessage(chatID, messageID, text, notifier)
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(paginationRow...))
+	if messageID == 0 {
+		notifier.SendTextWithKeyboard(chatID, text, keyboard)
+		return
+	}
+	notifier.EditMessageWithKeyboard(chatID, messageID, text, keyboard)
+}
+
+func auditCallbackData(offset int, filter string) string {
+	return fmt.Sprintf("%s%d_%s", auditCallbackPrefix, offset, filter)
+}
+
+func formatAuditEntry(entry audit.Entry) string {
+	result := "✅"
+	if entry.Result != audit.ResultOK {
+		result = "❌"
+	}
+	target := utils.Truncate(entry.Container, 40)
+	if target == "" && entry.ContainerID != "" {
+		target = entry.ContainerID[:utils.Min(12, len(entry.ContainerID))]
+	}
+	line := fmt.Sprintf(
+		"<code>%s</code> %s <b>%s</b> <u>%s</u> by %s (%s)",
+		entry.Time.Format("01-02 15:04:05"),
+		result,
+		utils.EscapeHTML(utils.Truncate(entry.Action, 40)),
+		utils.EscapeHTML(target),
+		utils.EscapeHTML(utils.Truncate(entry.User, 40)),
+		entry.Duration().Round(time.Millisecond),
+	)
+	if entry.Error != "" {
+		line += "\n    <i>" + utils.EscapeHTML(utils.Truncate(entry.Error, 200)) + "</i>"
+	}
+	return line
+}
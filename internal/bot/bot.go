@@ -3,17 +3,29 @@ package bot
 import (
 	"fmt"
 	"log"
-	"os"
 	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/auth"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/config"
+)
+
+var (
+	TelegramBot *tgbotapi.BotAPI
+	botConfig   *config.Config
 )
 
-var TelegramBot *tgbotapi.BotAPI
+func InitTelegramBot(cfg *config.Config) error {
+	botConfig = cfg
 
-func InitTelegramBot() error {
 	var err error
-	TelegramBot, err = tgbotapi.NewBotAPI(os.Getenv("TELEGRAM_BOT_TOKEN"))
+	accessPolicy, err = auth.NewPolicy(cfg.AccessChats, cfg.AccessUsers)
+	if err != nil {
+		return fmt.Errorf("invalid access configuration: %v", err)
+	}
+
+	TelegramBot, err = tgbotapi.NewBotAPI(cfg.TelegramBotToken)
 	if err != nil {
 		return fmt.Errorf("failed to initialize Telegram bot: %v", err)
 	}
@@ -51,6 +63,31 @@ func (n *TelegramNotifier) SendTextWithKeyboard(chatID int64, message string, ke
 	return sentMsg.MessageID
 }
 
+func (n *TelegramNotifier) SendDocument(chatID int64, fileName string, data []byte, caption string) int {
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: fileName, Bytes: data})
+	doc.Caption = strings.ToValidUTF8(caption, "")
+	doc.ParseMode = tgbotapi.ModeHTML
+	sentMsg, err := n.Bot.Send(doc)
+	if err != nil {
+		log.Printf("Error sending document: %v", err)
+		return 0
+	}
+	return sentMsg.MessageID
+}
+
+func (n *TelegramNotifier) SendDocumentWithKeyboard(chatID int64, fileName string, data []byte, caption string, keyboard tgbotapi.InlineKeyboardMarkup) int {
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: fileName, Bytes: data})
+	doc.Caption = strings.ToValidUTF8(caption, "")
+	doc.ParseMode = tgbotapi.ModeHTML
+	doc.ReplyMarkup = keyboard
+	sentMsg, err := n.Bot.Send(doc)
+	if err != nil {
+		log.Printf("Error sending document with keyboard: %v", err)
+		return 0
+	}
+	return sentMsg.MessageID
+}
+
 func (n *TelegramNotifier) EditMessageText(chatID int64, messageID int, text string) {
 	validText := strings.ToValidUTF8(text, "")
 	editMsg := tgbotapi.NewEditMessageText(chatID, messageID, validText)
@@ -71,6 +108,16 @@ func (n *TelegramNotifier) EditMessageWithKeyboard(chatID int64, messageID int,
 	}
 }
 
+func (n *TelegramNotifier) EditMessageCaptionWithKeyboard(chatID int64, messageID int, caption string, keyboard tgbotapi.InlineKeyboardMarkup) {
+	editMsg := tgbotapi.NewEditMessageCaption(chatID, messageID, strings.ToValidUTF8(caption, ""))
+	editMsg.ParseMode = tgbotapi.ModeHTML
+	editMsg.ReplyMarkup = &keyboard
+	_, err := n.Bot.Send(editMsg)
+	if err != nil {
+		log.Printf("Error editing message caption with keyboard: %v", err)
+	}
+}
+
 func (n *TelegramNotifier) AnswerCallbackQuery(callbackID string, text string) {
 	answer := tgbotapi.NewCallback(callbackID, text)
 	if _, err := n.Bot.Request(answer); err != nil {
@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/alert"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/callback"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/docker"
+)
+
+var (
+	unsignedContainerPrefixes = []string{"container_", "page_", "action_", execCallbackPrefix, logsCallbackPrefix, alert.MuteCallbackPrefix}
+	unsignedAlertPrefixes     = []string{alert.CallbackPrefix, alert.AckCallbackPrefix}
+)
+
+func unsignedContainerCallback(data string) bool {
+	return hasAnyPrefix(data, unsignedContainerPrefixes)
+}
+
+func unsignedAlertCallback(data string) bool {
+	return hasAnyPrefix(data, unsignedAlertPrefixes)
+}
+
+func hasAnyPrefix(data string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(data, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func resolveSignedCallback(chatID int64, data string, state *BotState) (string, error) {
+	payload, err := callback.Decode(data, chatID)
+	if err != nil {
+		return "", err
+	}
+	if payload.Action == "page" {
+		return fmt.Sprintf("page_%d", payload.Page), nil
+	}
+	if payload.Action == alert.AckAction {
+		return fmt.Sprintf("%s%d", alert.AckCallbackPrefix, payload.Arg), nil
+	}
+
+	shortID := payload.ContainerID
+	if container, err := docker.DockerClient.ContainerInspect(context.Background(), payload.ContainerID); err == nil {
+		shortID = container.ID[:12]
+		state.ShortIDMap[shortID] = container.ID
+	}
+	if payload.Action == "exec" {
+		return fmt.Sprintf("%s%d_%s", execCallbackPrefix, payload.Arg, shortID), nil
+	}
+	if strings.HasPrefix(payload.Action, logsCallbackPrefix) {
+		return fmt.Sprintf("%s_%d_%s", payload.Action, payload.Arg, shortID), nil
+	}
+	if strings.HasPrefix(payload.Action, alert.CallbackPrefix) {
+		return fmt.Sprintf("%s_%d_%s", payload.Action, payload.Arg, shortID), nil
+	}
+	state.CurrentPage = payload.Page
+
+	switch {
+	case payload.Action == "open":
+		return "container_" + shortID, nil
+	case strings.HasPrefix(payload.Action, alert.MuteCallbackPrefix):
+		return payload.Action + "_" + shortID, nil
+	}
+	return fmt.Sprintf("action_%s_%s", payload.Action, shortID), nil
+}
+
+func signedCallbackError(err error) string {
+	switch {
+	case errors.Is(err, callback.ErrExpired):
+		return "⌛ This button has expired, please use /list again"
+	case errors.Is(err, callback.ErrWrongChat):
+		return "❌ This button belongs to another chat"
+	}
+	return "❌ Invalid button"
+}
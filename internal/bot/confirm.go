@@ -0,0 +1,208 @@
+package bot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/auth"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/docker"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const (
+	confirmCallbackPrefix = "confirm_"
+	cancelCallbackPrefix  = "cancel_"
+
+	protectedLabel = "docker-monitor.protected"
+)
+
+type pendingConfirmation struct {
+	ChatID      int64
+	UserID      int64
+	User        string
+	MessageID   int
+	Action      string
+	ShortID     string
+	ContainerID string
+	Name        string
+	Typed       bool
+	ExpiresAt   time.Time
+}
+
+var (
+	confirmations   = make(map[string]*pendingConfirmation)
+	confirmationMux = &sync.Mutex{}
+)
+
+func needsConfirmation(action string) bool {
+	for _, confirmAction := range botConfig.ConfirmActions {
+		if confirmAction == action {
+			return true
+		}
+	}
+	return false
+}
+
+func requestConfirmation(chatID int64, messageID int, action, shortID, fullID string, from *tgbotapi.User, notifier notification.Notifier) bool {
+	container, err := docker.DockerClient.ContainerInspect(context.Background(), fullID)
+	if err != nil {
+		editOrSendErrorMessage(chatID, messageID, fmt.Sprintf("Failed to inspect container: %v", err), notifier)
+		return true
+	}
+	protected := false
+	if container.Config != nil {
+		protected, _ = strconv.ParseBool(container.Config.Labels[protectedLabel])
+	}
+	typed := protected && action != "start"
+	if !typed && !needsConfirmation(baseAction(action)) {
+		return false
+	}
+
+	tokenBytes := make([]byte, 8)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		editOrSendErrorMessage(chatID, messageID, fmt.Sprintf("Failed to create confirmation: %v", err), notifier)
+		return true
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	pending := &pendingConfirmation{
+		ChatID:      chatID,
+		User:        userName(from),
+		MessageID:   messageID,
+		Action:      action,
+		ShortID:     shortID,
+		ContainerID: fullID,
+		Name:        strings.TrimPrefix(container.Name, "/"),
+		Typed:       typed,
+		ExpiresAt:   time.Now().Add(botConfig.ConfirmationTimeout),
+	}
+	if from != nil {
+		pending.UserID = from.ID
+	}
+
+	confirmationMux.Lock()
+	pruneConfirmationsLocked(time.Now())
+	confirmations[token] = pending
+	confirmationMux.Unlock()
+
+	cancelButton := tgbotapi.NewInlineKeyboardButtonData("❌ No", cancelCallbackPrefix+token)
+	if typed {
+		text := fmt.Sprintf(
+			"🛡 <b>%s</b> is protected.\n\nTo %s it, %s must reply with the container name within %s.",
+			utils.EscapeHTML(pending.Name),
+			actionLabel(action),
+			utils.EscapeHTML(pending.User),
+			botConfig.ConfirmationTimeout,
+		)
+		cancelButton.Text = "❌ Cancel"
+		notifier.EditMessageWithKeyboard(chatID, messageID, text, tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(cancelButton)))
+		return true
+	}
+
+	text := fmt.Sprintf("⚠️ Are you sure you want to %s <b>%s</b>?", actionLabel(action), utils.EscapeHTML(pending.Name))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Yes", confirmCallbackPrefix+token),
+		cancelButton,
+	))
+	notifier.EditMessageWithKeyboard(chatID, messageID, text, keyboard)
+	return true
+}
+
+func handleConfirmCallback(chatID int64, messageID int, data string, from *tgbotapi.User, notifier notification.Notifier, state *BotState) {
+	pending, ok := takeConfirmation(strings.TrimPrefix(data, confirmCallbackPrefix), from, false, chatID, notifier)
+	if !ok {
+		return
+	}
+	state.ShortIDMap[pending.ShortID] = pending.ContainerID
+	executeContainerAction(chatID, messageID, pending.Action, pending.ShortID, pending.ContainerID, from, notifier, state)
+}
+
+func handleCancelCallback(chatID int64, messageID int, data string, from *tgbotapi.User, notifier notification.Notifier, state *BotState) {
+	pending, ok := takeConfirmation(strings.TrimPrefix(data, cancelCallbackPrefix), from, true, chatID, notifier)
+	if !ok {
+		return
+	}
+	state.ShortIDMap[pending.ShortID] = pending.ContainerID
+	showContainerDetails(chatID, messageID, pending.ShortID, notifier, state)
+}
+
+func takeConfirmation(token string, from *tgbotapi.User, allowTyped bool, chatID int64, notifier notification.Notifier) (*pendingConfirmation, bool) {
+	confirmationMux.Lock()
+	defer confirmationMux.Unlock()
+
+	pending, ok := confirmations[token]
+	if !ok || time.Now().After(pending.ExpiresAt) {
+		delete(confirmations, token)
+		notifier.SendText(chatID, "⌛ This confirmation has expired, please try again")
+		return nil, false
+	}
+	if from == nil || from.ID != pending.UserID {
+		notifier.SendText(chatID, fmt.Sprintf("❌ Only %s can confirm this action", utils.EscapeHTML(pending.User)))
+		return nil, false
+	}
+	if pending.Typed && !allowTyped {
+		return nil, false
+	}
+	delete(confirmations, token)
+	return pending, true
+}
+
+func HandleMessage(bot *tgbotapi.BotAPI, msg *tgbotapi.Message, notifier notification.Notifier) {
+	if msg.From == nil {
+		return
+	}
+	chatID := msg.Chat.ID
+
+	if !authorize(chatID, msg.From, auth.RoleOperator) {
+		return
+	}
+	text := strings.TrimSpace(msg.Text)
+	isReply := msg.ReplyToMessage != nil
+
+	confirmationMux.Lock()
+	pruneConfirmationsLocked(time.Now())
+	var token string
+	var pending *pendingConfirmation
+	for t, p := range confirmations {
+		if !p.Typed || p.ChatID != chatID || p.UserID != msg.From.ID {
+			continue
+		}
+		if text == p.Name || (isReply && msg.ReplyToMessage.MessageID == p.MessageID) {
+			token, pending = t, p
+			break
+		}
+	}
+	if pending != nil {
+		delete(confirmations, token)
+	}
+	confirmationMux.Unlock()
+	if pending == nil {
+		return
+	}
+
+	state := getState(chatID)
+	state.ShortIDMap[pending.ShortID] = pending.ContainerID
+	if text != pending.Name {
+		notifier.SendText(chatID, fmt.Sprintf("❌ Container name does not match, <i>%s</i> of <b>%s</b> cancelled", actionLabel(pending.Action), utils.EscapeHTML(pending.Name)))
+		showContainerDetails(chatID, pending.MessageID, pending.ShortID, notifier, state)
+		return
+	}
+	executeContainerAction(chatID, pending.MessageID, pending.Action, pending.ShortID, pending.ContainerID, msg.From, notifier, state)
+}
+
+func pruneConfirmationsLocked(now time.Time) {
+	for token, pending := range confirmations {
+		if now.After(pending.ExpiresAt) {
+			delete(confirmations, token)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/callback"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/docker"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const (
+	execCallbackPrefix = "exec_"
+	execUpdateInterval = 2 * time.Second
+	execOutputLimit    = 3500
+)
+
+func execCommandKey(name string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return h.Sum32()
+}
+
+func handleExecCommand(chatID int64, msg *tgbotapi.Message, notifier notification.Notifier) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 {
+		notifier.SendText(chatID, "Usage: <code>/exec &lt;container&gt; [command]</code>")
+		return
+	}
+
+	container, err := docker.DockerClient.ContainerInspect(context.Background(), args[0])
+	if err != nil {
+		notifier.SendText(chatID, fmt.Sprintf("❌ Container <b>%s</b> not found", utils.EscapeHTML(args[0])))
+		return
+	}
+	name := strings.TrimPrefix(container.Name, "/")
+	commands := docker.ExecCommandsFor(container)
+	if len(commands) == 0 {
+		notifier.SendText(chatID, fmt.Sprintf("No commands are allowed for <b>%s</b>", utils.EscapeHTML(name)))
+		return
+	}
+
+	if len(args) > 1 {
+		for _, command := range commands {
+			if command.Name == args[1] {
+				go runExec(chatID, container.ID, name, command, msg.From, notifier)
+				return
+			}
+		}
+		notifier.SendText(chatID, fmt.Sprintf("❌ Command <b>%s</b> is not allowed for <b>%s</b>", utils.EscapeHTML(args[1]), utils.EscapeHTML(name)))
+		return
+	}
+
+	var lines []string
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, command := range commands {
+		lines = append(lines, fmt.Sprintf("• <b>%s</b>: <code>%s</code>", utils.EscapeHTML(command.Name), utils.EscapeHTML(strings.Join(command.Cmd, " "))))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			"⚙️ "+command.Name,
+			callback.NewWithArg("exec", container.ID, execCommandKey(command.Name), chatID),
+		)))
+	}
+	text := fmt.Sprintf("⚙️ <b>Commands for <u>%s</u>:</b>\n\n%s", utils.EscapeHTML(name), strings.Join(lines, "\n"))
+	notifier.SendTextWithKeyboard(chatID, text, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows})
+}
+
+func handleExecCallback(chatID int64, data string, from *tgbotapi.User, notifier notification.Notifier, state *BotState) {
+	parts := strings.Split(strings.TrimPrefix(data, execCallbackPrefix), "_")
+	if len(parts) != 2 {
+		return
+	}
+	fullID, exists := state.ShortIDMap[parts[1]]
+	if !exists {
+		notifier.SendText(chatID, "❌ Container not found")
+		return
+	}
+	container, err := docker.DockerClient.ContainerInspect(context.Background(), fullID)
+	if err != nil {
+		notifier.SendText(chatID, "❌ Container not found")
+		return
+	}
+
+	for _, command := range docker.ExecCommandsFor(container) {
+		if fmt.Sprint(execCommandKey(command.Name)) == parts[0] {
+			go runExec(chatID, container.ID, strings.TrimPrefix(container.Name, "/"), command, from, notifier)
+			return
+		}
+	}
+	notifier.SendText(chatID, "❌ This command is no longer allowed for the container")
+}
+
+func runExec(chatID int64, containerID, name string, command docker.ExecCommand, from *tgbotapi.User, notifier notification.Notifier) {
+	if !requireElevation(chatID, "exec", from, notifier) {
+		return
+	}
+
+	header := fmt.Sprintf(
+		"⚙️ <b>%s</b> in <u>%s</u> by %s\n<code>%s</code>\n\n",
+		utils.EscapeHTML(command.Name),
+		utils.EscapeHTML(name),
+		utils.EscapeHTML(userName(from)),
+		utils.EscapeHTML(strings.Join(command.Cmd, " ")),
+	)
+	messageID := notifier.SendText(chatID, header+"⏳ Running...")
+	if messageID == 0 {
+		return
+	}
+
+	var mu sync.Mutex
+	var output strings.Builder
+	render := func(status string) string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		text := utils.RemoveControlCharactersRegex(strings.ToValidUTF8(output.String(), ""))
+		if runes := []rune(text); len(runes) > execOutputLimit {
+			text = "…" + string(runes[len(runes)-execOutputLimit:])
+		}
+		if strings.TrimSpace(text) == "" {
+			return header + status
+		}
+		return header + "<pre>" + utils.EscapeHTML(text) + "</pre>\n" + status
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), botConfig.ExecTimeout)
+	defer cancel()
+
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(execUpdateInterval)
+		defer ticker.Stop()
+		last := ""
+		for {
+			select {
+			case <-ticker.C:
+				if text := render("⏳ Running..."); text != last {
+					notifier.EditMessageText(chatID, messageID, text)
+					last = text
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	startedAt := time.Now()
+	exitCode, err := docker.Exec(ctx, containerID, command.Cmd, func(line docker.LogLine) {
+		mu.Lock()
+		output.WriteString(line.Text + "\n")
+		mu.Unlock()
+	})
+	duration := time.Since(startedAt)
+	close(done)
+	<-stopped
+
+	status := fmt.Sprintf("✅ Exit code %d after %s", exitCode, duration.Round(time.Millisecond))
+	auditErr := err
+	switch {
+	case err == context.DeadlineExceeded:
+		status = fmt.Sprintf("⌛ Timed out after %s, the command may still be running", botConfig.ExecTimeout)
+		auditErr = fmt.Errorf("timed out after %s", botConfig.ExecTimeout)
+	case err != nil:
+		status = "❌ " + utils.EscapeHTML(err.Error())
+	case exitCode != 0:
+		status = fmt.Sprintf("❌ Exit code %d after %s", exitCode, duration.Round(time.Millisecond))
+		auditErr = fmt.Errorf("exit code %d", exitCode)
+	}
+	notifier.EditMessageText(chatID, messageID, render(status))
+	recordAction(chatID, containerID, name, "exec "+command.Name, from, auditErr, duration)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -11,8 +12,13 @@ import (
 	"github.com/docker/docker/api/types"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/alert"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/audit"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/callback"
 	"github.com/HarkushaVlad/docker-monitor-bot/internal/docker"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/incident"
 	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
 )
 
 const itemsPerPage = 6
@@ -21,6 +27,7 @@ type BotState struct {
 	LastMessageID int
 	CurrentPage   int
 	ShortIDMap    map[string]string
+	IncidentsPage int
 }
 
 var (
@@ -56,6 +63,16 @@ func HandleCallbacks(bot *tgbotapi.BotAPI, notifier notification.Notifier) {
 
 func HandleCommand(bot *tgbotapi.BotAPI, msg *tgbotapi.Message, notifier notification.Notifier) {
 	chatID := msg.Chat.ID
+
+	required, known := commandRoles[msg.Command()]
+	if !known {
+		return
+	}
+	if !authorize(chatID, msg.From, required) {
+		reportDenied(chatID, msg.From, msg.Text, required, notifier)
+		notifier.SendText(chatID, "⛔ You are not allowed to use this command")
+		return
+	}
 	state := getState(chatID)
 
 	switch msg.Command() {
@@ -68,6 +85,23 @@ func HandleCommand(bot *tgbotapi.BotAPI, msg *tgbotapi.Message, notifier notific
 		state.LastMessageID = 0
 		state.CurrentPage = 0
 		showContainerList(chatID, state, notifier)
+	case "mute":
+		handleMuteCommand(chatID, msg, notifier)
+	case "unmute":
+		handleUnmuteCommand(chatID, msg, notifier)
+	case "silences":
+		handleSilencesCommand(chatID, notifier)
+	case "incidents":
+		state.IncidentsPage = 0
+		showIncidentList(chatID, 0, state, notifier)
+	case "audit":
+		handleAuditCommand(chatID, msg, notifier)
+	case "2fa":
+		handleTwoFactorCommand(chatID, msg, notifier)
+	case "exec":
+		handleExecCommand(chatID, msg, notifier)
+	case "logs":
+		handleLogsCommand(chatID, msg, notifier)
 	}
 }
 
@@ -77,6 +111,28 @@ func HandleCallbackQuery(bot *tgbotapi.BotAPI, query *tgbotapi.CallbackQuery, no
 	data := query.Data
 	state := getState(chatID)
 
+	if strings.HasPrefix(data, callback.Prefix) {
+		resolved, err := resolveSignedCallback(chatID, data, state)
+		if err != nil {
+			log.Printf("Rejected callback from user %d in chat %d: %v", query.From.ID, chatID, err)
+			notifier.AnswerCallbackQuery(query.ID, signedCallbackError(err))
+			return
+		}
+		data = resolved
+	} else if unsignedContainerCallback(data) {
+		notifier.AnswerCallbackQuery(query.ID, "⌛ This button is outdated, please use /list again")
+		return
+	} else if unsignedAlertCallback(data) {
+		notifier.AnswerCallbackQuery(query.ID, "⌛ This alert was sent by an older version of the bot, its buttons no longer work")
+		return
+	}
+
+	if required := callbackRole(data); !authorize(chatID, query.From, required) {
+		reportDenied(chatID, query.From, data, required, notifier)
+		notifier.AnswerCallbackQuery(query.ID, "⛔ You are not allowed to do this")
+		return
+	}
+
 	notifier.AnswerCallbackQuery(query.ID, "")
 
 	switch {
@@ -86,7 +142,29 @@ func HandleCallbackQuery(bot *tgbotapi.BotAPI, query *tgbotapi.CallbackQuery, no
 	case strings.HasPrefix(data, "page_"):
 		handlePageNavigation(chatID, data, notifier, state)
 	case strings.HasPrefix(data, "action_"):
-		handleContainerAction(chatID, msgID, data, notifier, state)
+		handleContainerAction(chatID, msgID, data, query.From, notifier, state)
+	case strings.HasPrefix(data, alert.CallbackPrefix):
+		handleAlertAction(chatID, data, notifier, state)
+	case strings.HasPrefix(data, alert.MuteCallbackPrefix):
+		handleMuteCallback(chatID, data, query.From, notifier)
+	case strings.HasPrefix(data, alert.AckCallbackPrefix):
+		handleAckCallback(chatID, data, query.From, notifier)
+	case strings.HasPrefix(data, incidentCallbackPrefix):
+		showIncidentTimeline(chatID, msgID, strings.TrimPrefix(data, incidentCallbackPrefix), notifier)
+	case strings.HasPrefix(data, incidentsPageCallbackPrefix):
+		handleIncidentsNavigation(chatID, msgID, data, notifier, state)
+	case strings.HasPrefix(data, confirmCallbackPrefix):
+		handleConfirmCallback(chatID, msgID, data, query.From, notifier, state)
+	case strings.HasPrefix(data, cancelCallbackPrefix):
+		handleCancelCallback(chatID, msgID, data, query.From, notifier, state)
+	case strings.HasPrefix(data, execCallbackPrefix):
+		handleExecCallback(chatID, data, query.From, notifier, state)
+	case strings.HasPrefix(data, logsCallbackPrefix):
+		handleLogsCallback(chatID, msgID, data, notifier, state)
+	case strings.HasPrefix(data, auditCallbackPrefix):
+		handleAuditCallback(chatID, msgID, data, notifier)
+	case strings.HasPrefix(data, unmuteCallbackPrefix):
+		unmute(chatID, "#"+strings.TrimPrefix(data, unmuteCallbackPrefix), notifier)
 	}
 }
 
@@ -137,7 +215,7 @@ func showContainerList(chatID int64, state *BotState, notifier notification.Noti
 
 		btn := tgbotapi.NewInlineKeyboardButtonData(
 			fmt.Sprintf("%s %s", getStatusIcon(container.State), getContainerName(container)),
-			fmt.Sprintf("container_%s", shortID),
+			callback.New("open", container.ID, state.CurrentPage, chatID),
 		)
 		buttons = append(buttons, btn)
 	}
@@ -153,10 +231,10 @@ func showContainerList(chatID int64, state *BotState, notifier notification.Noti
 
 	var paginationRow []tgbotapi.InlineKeyboardButton
 	if state.CurrentPage > 0 {
-		paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData("⬅", "page_prev"))
+		paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData("⬅", callback.New("page", "", state.CurrentPage-1, chatID)))
 	}
 	if state.CurrentPage < totalPages-1 {
-		paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData("➡", "page_next"))
+		paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData("➡", callback.New("page", "", state.CurrentPage+1, chatID)))
 	}
 	if len(paginationRow) > 0 {
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(paginationRow...))
@@ -187,10 +265,17 @@ func showContainerDetails(chatID int64, messageID int, shortID string, notifier
 	}
 
 	status := "🔴 Stopped"
-	if container.State.Running {
+	if container.State.Paused {
+		status = "⏸ Paused"
+	} else if container.State.Running {
 		status = "🟢 Running"
 	}
 
+	health := "—"
+	if container.State.Health != nil && docker.HealthLabel(container.State.Health.Status) != "" {
+		health = docker.HealthLabel(container.State.Health.Status)
+	}
+
 	createdTime, err := time.Parse(time.RFC3339Nano, container.Created)
 	if err != nil {
 		log.Printf("Error parsing creation time: %v", err)
@@ -201,52 +286,49 @@ func showContainerDetails(chatID int64, messageID int, shortID string, notifier
 		"<pre>"+
 			"┌ Name: %s\n"+
 			"├ Status: %s\n"+
+			"├ Health: %s\n"+
 			"├ Image: %s\n"+
 			"└ Created: %s"+
 			"</pre>",
 		strings.TrimPrefix(container.Name, "/"),
 		status,
+		health,
 		container.Config.Image,
 		createdTime.Format("2006-01-02 15:04:05"),
 	)
+	if container.State.Health != nil && container.State.Health.Status == types.Unhealthy {
+		if probe := docker.LastHealthProbe(container.State.Health); probe != nil {
+			text += "\n\n🩺 <b>Last failing probe:</b>\n" + docker.FormatHealthProbe(probe)
+		}
+	}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("▶️ Start", fmt.Sprintf("action_start_%s", shortID)),
-			tgbotapi.NewInlineKeyboardButtonData("⏹️ Stop", fmt.Sprintf("action_stop_%s", shortID)),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔄 Restart", fmt.Sprintf("action_restart_%s", shortID)),
-			tgbotapi.NewInlineKeyboardButtonData("↩️ Back", "page_back"),
-		),
-	)
+	keyboard := containerActionsKeyboard(chatID, container, state)
 
+	if messageID == 0 {
+		state.LastMessageID = notifier.SendTextWithKeyboard(chatID, text, keyboard)
+		return
+	}
 	notifier.EditMessageWithKeyboard(chatID, messageID, text, keyboard)
 	state.LastMessageID = messageID
 }
 
 func handlePageNavigation(chatID int64, action string, notifier notification.Notifier, state *BotState) {
-	switch strings.TrimPrefix(action, "page_") {
-	case "prev":
-		if state.CurrentPage > 0 {
-			state.CurrentPage--
-		}
-	case "next":
-		state.CurrentPage++
-	case "back":
-		state.CurrentPage = 0
+	page, err := strconv.Atoi(strings.TrimPrefix(action, "page_"))
+	if err != nil || page < 0 {
+		return
 	}
+	state.CurrentPage = page
 
 	showContainerList(chatID, state, notifier)
 }
 
-func handleContainerAction(chatID int64, messageID int, action string, notifier notification.Notifier, state *BotState) {
+func handleContainerAction(chatID int64, messageID int, action string, from *tgbotapi.User, notifier notification.Notifier, state *BotState) {
 	parts := strings.Split(action, "_")
 	if len(parts) < 3 {
 		return
 	}
-	actionType := parts[1]
-	shortID := parts[2]
+	actionType := strings.Join(parts[1:len(parts)-1], "_")
+	shortID := parts[len(parts)-1]
 
 	fullID, exists := state.ShortIDMap[shortID]
 	if !exists {
@@ -254,22 +336,67 @@ func handleContainerAction(chatID int64, messageID int, action string, notifier
 		return
 	}
 
+	switch actionType {
+	case "kill":
+		showKillOptions(chatID, messageID, fullID, notifier, state)
+		return
+	case "remove":
+		showRemoveOptions(chatID, messageID, fullID, notifier, state)
+		return
+	}
+
+	if !requireElevation(chatID, baseAction(actionType), from, notifier) {
+		return
+	}
+	if requestConfirmation(chatID, messageID, actionType, shortID, fullID, from, notifier) {
+		return
+	}
+	executeContainerAction(chatID, messageID, actionType, shortID, fullID, from, notifier, state)
+}
+
+func executeContainerAction(chatID int64, messageID int, actionType, shortID, fullID string, from *tgbotapi.User, notifier notification.Notifier, state *BotState) {
 	ctx := context.Background()
-	var err error
+	container, err := docker.DockerClient.ContainerInspect(ctx, fullID)
+	if err != nil {
+		editOrSendErrorMessage(chatID, messageID, "Container not found", notifier)
+		return
+	}
+	name := strings.TrimPrefix(container.Name, "/")
+	timeout := docker.StopTimeout(container, botConfig.StopTimeout)
+	startedAt := time.Now()
 
-	switch actionType {
-	case "start":
+	switch {
+	case actionType == "start":
 		err = docker.DockerClient.ContainerStart(ctx, fullID, types.ContainerStartOptions{})
-	case "stop":
-		timeout := 10 * time.Second
+	case actionType == "stop":
 		err = docker.DockerClient.ContainerStop(ctx, fullID, &timeout)
-	case "restart":
-		timeout := 10 * time.Second
+	case actionType == "restart":
 		err = docker.DockerClient.ContainerRestart(ctx, fullID, &timeout)
+	case actionType == "pause":
+		err = docker.DockerClient.ContainerPause(ctx, fullID)
+	case actionType == "unpause":
+		err = docker.DockerClient.ContainerUnpause(ctx, fullID)
+	case strings.HasPrefix(actionType, "kill_"):
+		err = docker.DockerClient.ContainerKill(ctx, fullID, strings.TrimPrefix(actionType, "kill_"))
+	case actionType == "remove_container", actionType == "remove_volumes":
+		err = docker.DockerClient.ContainerRemove(ctx, fullID, types.ContainerRemoveOptions{
+			RemoveVolumes: actionType == "remove_volumes",
+			Force:         true,
+		})
+	default:
+		return
 	}
 
+	recordAction(chatID, fullID, name, actionType, from, err, time.Since(startedAt))
 	if err != nil {
-		editOrSendErrorMessage(chatID, messageID, fmt.Sprintf("Failed to %s container: %v", actionType, err), notifier)
+		editOrSendErrorMessage(chatID, messageID, fmt.Sprintf("Failed to %s container: %v", actionLabel(actionType), err), notifier)
+		return
+	}
+
+	if baseAction(actionType) == "remove" {
+		notifier.EditMessageText(chatID, messageID, fmt.Sprintf("🗑 Container <u><b>%s</b></u> removed", utils.EscapeHTML(name)))
+		state.LastMessageID = messageID
+		showContainerList(chatID, state, notifier)
 		return
 	}
 
@@ -278,13 +405,45 @@ func handleContainerAction(chatID int64, messageID int, action string, notifier
 	showContainerDetails(chatID, messageID, shortID, notifier, state)
 }
 
+func recordAction(chatID int64, containerID, name, actionType string, from *tgbotapi.User, actionErr error, duration time.Duration) {
+	entry := audit.Entry{
+		User:        userName(from),
+		ChatID:      chatID,
+		Action:      actionType,
+		ContainerID: containerID,
+		Container:   name,
+		Result:      audit.ResultOK,
+		DurationMs:  duration.Milliseconds(),
+	}
+	if from != nil {
+		entry.UserID = from.ID
+	}
+	if actionErr != nil {
+		entry.Result = audit.ResultFailed
+		entry.Error = actionErr.Error()
+	}
+	if err := audit.Record(entry); err != nil {
+		log.Printf("Error writing audit entry: %v", err)
+	}
+
+	detail := fmt.Sprintf("Manual %s by %s", actionLabel(actionType), userName(from))
+	if actionErr != nil {
+		detail += " failed: " + actionErr.Error()
+	}
+	incident.Record(containerID, name, incident.KindAction, detail, time.Now())
+}
+
 func formatContainerInfo(container types.Container) string {
 	createdTime := time.Unix(container.Created, 0)
+	status := getStatusIcon(container.State)
+	if health := docker.HealthLabel(docker.HealthFromListStatus(container.Status)); health != "" {
+		status += " " + health
+	}
 	return fmt.Sprintf(
 		"<pre>┌ ID: %s\n├ Name: %s\n├ Status: %s\n├ Image: %s\n└ Started: %s</pre>",
 		container.ID[:12],
 		getContainerName(container),
-		getStatusIcon(container.State),
+		status,
 		container.Image,
 		createdTime.Format("2006-01-02 15:04:05"),
 	)
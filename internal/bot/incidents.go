@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/incident"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const (
+	incidentCallbackPrefix      = "incident_"
+	incidentsPageCallbackPrefix = "incidents_"
+)
+
+func showIncidentList(chatID int64, messageID int, state *BotState, notifier notification.Notifier) {
+	incidents := incident.List()
+	if len(incidents) == 0 {
+		editOrSendMessage(chatID, messageID, "🧯 <b>No incidents recorded</b>", notifier)
+		return
+	}
+
+	totalPages := (len(incidents)-1)/itemsPerPage + 1
+	if state.IncidentsPage >= totalPages {
+		state.IncidentsPage = totalPages - 1
+	}
+	start := state.IncidentsPage * itemsPerPage
+	end := start + itemsPerPage
+	if end > len(incidents) {
+		end = len(incidents)
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, i := range incidents[start:end] {
+		status := "✅"
+		if i.Open() {
+			status = "🔴"
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%s #%s %s · %s · %s", status, i.ID, i.Container, i.StartedAt.Format("01-02 15:04"), incidentDuration(i)),
+			incidentCallbackPrefix+i.ID,
+		)))
+	}
+
+	var paginationRow []tgbotapi.InlineKeyboardButton
+	if state.IncidentsPage > 0 {
+		paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData("⬅", incidentsPageCallbackPrefix+"prev"))
+	}
+	if state.IncidentsPage < totalPages-1 {
+		paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData("➡", incidentsPageCallbackPrefix+"next"))
+	}
+	if len(paginationRow) > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(paginationRow...))
+	}
+
+	keyboard := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+	msgText := fmt.Sprintf("🧯 Incidents (%d-%d of %d):", start+1, end, len(incidents))
+
+	if messageID == 0 {
+		notifier.SendTextWithKeyboard(chatID, msgText, keyboard)
+		return
+	}
+	notifier.EditMessageWithKeyboard(chatID, messageID, msgText, keyboard)
+}
+
+func handleIncidentsNavigation(chatID int64, messageID int, data string, notifier notification.Notifier, state *BotState) {
+	switch strings.TrimPrefix(data, incidentsPageCallbackPrefix) {
+	case "prev":
+		if state.IncidentsPage > 0 {
+			state.IncidentsPage--
+		}
+	case "next":
+		state.IncidentsPage++
+	}
+
+	showIncidentList(chatID, messageID, state, notifier)
+}
+
+func showIncidentTimeline(chatID int64, messageID int, id string, notifier notification.Notifier) {
+	i, ok := incident.Get(id)
+	if !ok {
+		editOrSendErrorMessage(chatID, messageID, "Incident not found", notifier)
+		return
+	}
+
+	ended := "ongoing"
+	if !i.Open() {
+		ended = i.EndedAt.Format("2006-01-02 15:04:05")
+	}
+	header := fmt.Sprintf(
+		"🧯 <b>Incident #%s · <u>%s</u></b>\n\n"+
+			"<pre>"+
+			"┌ Started: %s\n"+
+			"├ Ended: %s\n"+
+			"└ Duration: %s"+
+			"</pre>\n\n"+
+			"<b>Timeline:</b>\n",
+		i.ID,
+		utils.EscapeHTML(i.Container),
+		i.StartedAt.Format("2006-01-02 15:04:05"),
+		ended,
+		incidentDuration(i),
+	)
+
+	var lines []string
+	for _, event := range i.Events {
+		layout := "15:04:05"
+		if event.At.YearDay() != i.StartedAt.YearDay() || event.At.Year() != i.StartedAt.Year() {
+			layout = "01-02 15:04:05"
+		}
+		lines = append(lines, fmt.Sprintf("<code>%s</code> %s %s", event.At.Format(layout), event.Kind.Icon(), utils.EscapeHTML(event.Detail)))
+	}
+
+	omitted := i.Dropped
+	var text string
+	for {
+		text = header
+		if omitted > 0 {
+			text += fmt.Sprintf("<i>…%d earlier events omitted</i>\n", omitted)
+		}
+		text += strings.Join(lines, "\n")
+		if utf8.RuneCountInString(text) <= telegramMessageLimit || len(lines) <= 1 {
+			break
+		}
+		lines = lines[1:]
+		omitted++
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("↩️ Back", incidentsPageCallbackPrefix+"back"),
+	))
+	notifier.EditMessageWithKeyboard(chatID, messageID, text, keyboard)
+}
+
+func incidentDuration(i incident.Incident) string {
+	end := i.EndedAt
+	if i.Open() {
+		end = time.Now()
+	}
+	return end.Sub(i.StartedAt).Round(time.Second).String()
+}
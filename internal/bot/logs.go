@@ -0,0 +1,381 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/callback"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/docker"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/silence"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const (
+	logsCallbackPrefix  = "logs_"
+	logsDefaultLines    = 50
+	logsMaxLines        = 1000
+	logsGrepScanLines   = 5000
+	logsOutputLimit     = 3500
+	logsUpdateInterval  = 3 * time.Second
+	logsDownloadLimit   = 45 << 20
+	logsReadTimeout     = 30 * time.Second
+	logsDownloadTimeout = 2 * time.Minute
+	logsMaxViews        = 200
+)
+
+type logView struct {
+	lines   int
+	pattern string
+	grep    *regexp.Regexp
+	since   time.Duration
+	cancel  context.CancelFunc
+}
+
+var (
+	logViews    = make(map[string]*logView)
+	logViewsMux = &sync.Mutex{}
+)
+
+func logViewKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("%d:%d", chatID, messageID)
+}
+
+func handleLogsCommand(chatID int64, msg *tgbotapi.Message, notifier notification.Notifier) {
+	view, containerRef, err := parseLogsArguments(strings.Fields(msg.CommandArguments()))
+	if err != nil {
+		notifier.SendText(chatID, "❌ "+utils.EscapeHTML(err.Error()))
+		return
+	}
+	if containerRef == "" {
+		notifier.SendText(chatID, "Usage: <code>/logs &lt;container&gt; [lines] [grep] [--since 1h]</code>")
+		return
+	}
+	go showLogs(chatID, containerRef, view, notifier)
+}
+
+func showLogs(chatID int64, containerRef string, view *logView, notifier notification.Notifier) {
+	ctx, cancel := context.WithTimeout(context.Background(), logsReadTimeout)
+	defer cancel()
+
+	container, err := docker.DockerClient.ContainerInspect(ctx, containerRef)
+	if err != nil {
+		notifier.SendText(chatID, fmt.Sprintf("❌ Container <b>%s</b> not found", utils.EscapeHTML(containerRef)))
+		return
+	}
+	name := strings.TrimPrefix(container.Name, "/")
+
+	lines, _, err := tailLogs(ctx, container.ID, view, time.Time{})
+	if err == nil && ctx.Err() != nil {
+		err = fmt.Errorf("timed out after %s", logsReadTimeout)
+	}
+	if err != nil {
+		notifier.SendText(chatID, fmt.Sprintf("❌ Error reading logs of <b>%s</b>: %s", utils.EscapeHTML(name), utils.EscapeHTML(err.Error())))
+		return
+	}
+
+	messageID := notifier.SendTextWithKeyboard(chatID, renderLogs(name, view, lines, ""), logsKeyboard(chatID, container.ID, view, false))
+	if messageID == 0 {
+		return
+	}
+
+	logViewsMux.Lock()
+	defer logViewsMux.Unlock()
+	if len(logViews) >= logsMaxViews {
+		for key, existing := range logViews {
+			if existing.cancel == nil {
+				delete(logViews, key)
+			}
+		}
+	}
+	logViews[logViewKey(chatID, messageID)] = view
+}
+
+func parseLogsArguments(args []string) (*logView, string, error) {
+	view := &logView{lines: logsDefaultLines}
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		value, isSince := "", false
+		switch {
+		case args[i] == "--since":
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("--since needs a duration, e.g. --since 1h")
+			}
+			value, isSince = args[i+1], true
+			i++
+		case strings.HasPrefix(args[i], "--since="):
+			value, isSince = strings.TrimPrefix(args[i], "--since="), true
+		}
+		if !isSince {
+			positional = append(positional, args[i])
+			continue
+		}
+		since, err := silence.ParseDuration(value)
+		if err != nil || since < time.Minute || since/time.Minute > math.MaxUint32 {
+			return nil, "", fmt.Errorf("invalid --since duration %q, use e.g. 30m, 1h or 2d", value)
+		}
+		view.since = since
+	}
+
+	if len(positional) == 0 {
+		return view, "", nil
+	}
+	containerRef := positional[0]
+	positional = positional[1:]
+	if len(positional) > 0 {
+		if n, err := strconv.Atoi(positional[0]); err == nil && n > 0 {
+			view.lines = utils.Min(n, logsMaxLines)
+			positional = positional[1:]
+		}
+	}
+	if len(positional) > 0 {
+		view.pattern = strings.Join(positional, " ")
+		grep, err := regexp.Compile("(?i)" + view.pattern)
+		if err != nil {
+			grep = regexp.MustCompile("(?i)" + regexp.QuoteMeta(view.pattern))
+		}
+		view.grep = grep
+	}
+	return view, containerRef, nil
+}
+
+func logsOptions(view *logView, since time.Time) types.ContainerLogsOptions {
+	options := types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Timestamps: true, Tail: strconv.Itoa(view.lines)}
+	if view.grep != nil {
+		options.Tail = strconv.Itoa(logsGrepScanLines)
+	}
+	if since.IsZero() && view.since > 0 {
+		since = time.Now().Add(-view.since)
+	}
+	if !since.IsZero() {
+		options.Since = fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond())
+	}
+	return options
+}
+
+func tailLogs(ctx context.Context, containerID string, view *logView, since time.Time) ([]string, time.Time, error) {
+	var lines []string
+	var last time.Time
+	err := docker.ReadContainerLogs(ctx, containerID, logsOptions(view, since), func(line docker.LogLine) {
+		lines = appendLogLine(lines, line, view)
+		last = line.Timestamp
+	})
+	return lines, last, err
+}
+
+func appendLogLine(lines []string, line docker.LogLine, view *logView) []string {
+	if view.grep != nil && !view.grep.MatchString(line.Text) {
+		return lines
+	}
+	lines = append(lines, line.Text)
+	if len(lines) > view.lines {
+		lines = lines[len(lines)-view.lines:]
+	}
+	return lines
+}
+
+func renderLogs(name string, view *logView, lines []string, status string) string {
+	details := []string{fmt.Sprintf("last %d lines", view.lines)}
+	if view.pattern != "" {
+		details = append(details, "matching <code>"+utils.EscapeHTML(view.pattern)+"</code>")
+	}
+	if view.since > 0 {
+		details = append(details, "since "+view.since.String())
+	}
+	header := fmt.Sprintf("📄 <b>Logs of <u>%s</u></b>\n<i>%s</i>\n\n", utils.EscapeHTML(name), strings.Join(details, " · "))
+
+	text := utils.RemoveControlCharactersRegex(strings.ToValidUTF8(strings.Join(lines, "\n"), ""))
+	if runes := []rune(text); len(runes) > logsOutputLimit {
+		text = "…" + string(runes[len(runes)-logsOutputLimit:])
+	}
+	body := "<i>No log lines</i>"
+	if strings.TrimSpace(text) != "" {
+		body = "<pre>" + utils.EscapeHTML(text) + "</pre>"
+	}
+	if status != "" {
+		body += "\n" + status
+	}
+	return header + body
+}
+
+func logsKeyboard(chatID int64, containerID string, view *logView, following bool) tgbotapi.InlineKeyboardMarkup {
+	sinceMinutes := uint32(view.since / time.Minute)
+	followButton := tgbotapi.NewInlineKeyboardButtonData("▶️ Follow", callback.NewWithArg("logs_follow", containerID, sinceMinutes, chatID))
+	if following {
+		followButton = tgbotapi.NewInlineKeyboardButtonData("⏹ Stop", callback.NewWithArg("logs_stop", containerID, sinceMinutes, chatID))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		followButton,
+		tgbotapi.NewInlineKeyboardButtonData("💾 Download", callback.NewWithArg("logs_download", containerID, sinceMinutes, chatID)),
+	))
+}
+
+func handleLogsCallback(chatID int64, messageID int, data string, notifier notification.Notifier, state *BotState) {
+	parts := strings.Split(strings.TrimPrefix(data, logsCallbackPrefix), "_")
+	if len(parts) != 3 {
+		return
+	}
+	fullID, exists := state.ShortIDMap[parts[2]]
+	if !exists {
+		notifier.SendText(chatID, "❌ Container not found")
+		return
+	}
+	container, err := docker.DockerClient.ContainerInspect(context.Background(), fullID)
+	if err != nil {
+		notifier.SendText(chatID, "❌ Container not found")
+		return
+	}
+	name := strings.TrimPrefix(container.Name, "/")
+
+	key := logViewKey(chatID, messageID)
+	logViewsMux.Lock()
+	view, exists := logViews[key]
+	if !exists {
+		sinceMinutes, _ := strconv.Atoi(parts[1])
+		view = &logView{lines: logsDefaultLines, since: time.Duration(sinceMinutes) * time.Minute}
+		logViews[key] = view
+	}
+	switch parts[0] {
+	case "follow":
+		if view.cancel == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), botConfig.LogsFollowDuration)
+			view.cancel = cancel
+			go followLogs(ctx, chatID, messageID, container.ID, name, view, notifier)
+		}
+	case "stop":
+		if view.cancel != nil {
+			view.cancel()
+		}
+	case "download":
+		go downloadLogs(chatID, container.ID, name, view.since, notifier)
+	}
+	logViewsMux.Unlock()
+}
+
+func followLogs(ctx context.Context, chatID int64, messageID int, containerID, name string, view *logView, notifier notification.Notifier) {
+	defer func() {
+		logViewsMux.Lock()
+		view.cancel()
+		view.cancel = nil
+		logViewsMux.Unlock()
+	}()
+
+	startedAt := time.Now()
+	lines, lastRead, err := tailLogs(ctx, containerID, view, time.Time{})
+	if err != nil {
+		notifier.EditMessageWithKeyboard(chatID, messageID, renderLogs(name, view, lines, "❌ "+utils.EscapeHTML(err.Error())), logsKeyboard(chatID, containerID, view, false))
+		return
+	}
+
+	var mu sync.Mutex
+	deadline, _ := ctx.Deadline()
+	following := logsKeyboard(chatID, containerID, view, true)
+	render := func(status string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		return renderLogs(name, view, lines, status)
+	}
+
+	last := render(fmt.Sprintf("🔴 Following until %s", deadline.Format("15:04:05")))
+	notifier.EditMessageWithKeyboard(chatID, messageID, last, following)
+
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(logsUpdateInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if text := render(fmt.Sprintf("🔴 Following until %s", deadline.Format("15:04:05"))); text != last {
+					notifier.EditMessageWithKeyboard(chatID, messageID, text, following)
+					last = text
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	if !lastRead.IsZero() {
+		startedAt = lastRead.Add(time.Nanosecond)
+	}
+	options := logsOptions(view, startedAt)
+	options.Follow = true
+	options.Tail = "all"
+	err = docker.ReadContainerLogs(ctx, containerID, options, func(line docker.LogLine) {
+		if !lastRead.IsZero() && !line.Timestamp.After(lastRead) {
+			return
+		}
+		mu.Lock()
+		lines = appendLogLine(lines, line, view)
+		mu.Unlock()
+	})
+	close(done)
+	<-stopped
+
+	status := "⏹ Follow stopped"
+	switch {
+	case err != nil:
+		log.Printf("Error following logs of %s: %v", name, err)
+		status = "❌ " + utils.EscapeHTML(err.Error())
+	case ctx.Err() == nil:
+		status = "⏹ Log stream ended, the container has stopped"
+	}
+	notifier.EditMessageWithKeyboard(chatID, messageID, render(status), logsKeyboard(chatID, containerID, view, false))
+}
+
+func downloadLogs(chatID int64, containerID, name string, since time.Duration, notifier notification.Notifier) {
+	options := types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Timestamps: true}
+	if since > 0 {
+		options.Since = strconv.FormatInt(time.Now().Add(-since).Unix(), 10)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), logsDownloadTimeout)
+	defer cancel()
+
+	var entries []string
+	size, truncated := 0, false
+	err := docker.ReadContainerLogs(ctx, containerID, options, func(line docker.LogLine) {
+		entry := fmt.Sprintf("%s [%s] %s\n", line.Timestamp.Format(time.RFC3339Nano), line.Stream, line.Text)
+		entries = append(entries, entry)
+		size += len(entry)
+		for size > logsDownloadLimit {
+			size -= len(entries[0])
+			entries = entries[1:]
+			truncated = true
+		}
+	})
+	timedOut := ctx.Err() != nil
+	if err != nil {
+		notifier.SendText(chatID, fmt.Sprintf("❌ Error reading logs of <b>%s</b>: %s", utils.EscapeHTML(name), utils.EscapeHTML(err.Error())))
+		return
+	}
+	if len(entries) == 0 {
+		notifier.SendText(chatID, fmt.Sprintf("📄 No log lines for <b>%s</b>", utils.EscapeHTML(name)))
+		return
+	}
+
+	caption := fmt.Sprintf("📄 <b>Logs of <u>%s</u></b>", utils.EscapeHTML(name))
+	if since > 0 {
+		caption += " since " + since.String()
+	}
+	if truncated {
+		caption += fmt.Sprintf("\n<i>Truncated to the newest %d MB, the older lines were dropped</i>", logsDownloadLimit>>20)
+	}
+	if timedOut {
+		caption += fmt.Sprintf("\n<i>Incomplete, reading the logs took longer than %s, the newest lines are missing</i>", logsDownloadTimeout)
+	}
+	fileName := fmt.Sprintf("%s-%s.log", name, time.Now().Format("20060102-150405"))
+	notifier.SendDocument(chatID, fileName, []byte(strings.Join(entries, "")), caption)
+}
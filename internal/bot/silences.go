@@ -0,0 +1,135 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/alert"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/docker"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/silence"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const unmuteCallbackPrefix = "unmute_"
+
+func handleMuteCommand(chatID int64, msg *tgbotapi.Message, notifier notification.Notifier) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) < 2 {
+		notifier.SendText(chatID, "Usage: <code>/mute &lt;container&gt; &lt;duration|forever&gt; [pattern]</code>\n\nExample: <code>/mute api 1h timeout</code>")
+		return
+	}
+
+	duration, err := silence.ParseDuration(args[1])
+	if err != nil {
+		notifier.SendText(chatID, "❌ "+utils.EscapeHTML(err.Error()))
+		return
+	}
+
+	s, err := silence.Add(args[0], duration, strings.Join(args[2:], " "), userName(msg.From))
+	if err != nil {
+		notifier.SendText(chatID, "❌ Failed to mute: "+utils.EscapeHTML(err.Error()))
+		return
+	}
+	notifier.SendText(chatID, "🔕 "+formatSilence(s))
+}
+
+func handleUnmuteCommand(chatID int64, msg *tgbotapi.Message, notifier notification.Notifier) {
+	ref := strings.TrimSpace(msg.CommandArguments())
+	if ref == "" {
+		notifier.SendText(chatID, "Usage: <code>/unmute &lt;container|#id|all&gt;</code>")
+		return
+	}
+	unmute(chatID, ref, notifier)
+}
+
+func unmute(chatID int64, ref string, notifier notification.Notifier) {
+	removed, err := silence.Remove(ref)
+	if err != nil {
+		notifier.SendText(chatID, "❌ Failed to unmute: "+utils.EscapeHTML(err.Error()))
+		return
+	}
+	if len(removed) == 0 {
+		notifier.SendText(chatID, fmt.Sprintf("No active silence matches <b>%s</b>", utils.EscapeHTML(ref)))
+		return
+	}
+
+	var lines []string
+	for _, s := range removed {
+		lines = append(lines, formatSilence(s))
+	}
+	notifier.SendText(chatID, "🔔 <b>Removed silences:</b>\n\n"+strings.Join(lines, "\n"))
+}
+
+func handleSilencesCommand(chatID int64, notifier notification.Notifier) {
+	active := silence.Active()
+	if len(active) == 0 {
+		notifier.SendText(chatID, "🔔 <b>No active silences</b>")
+		return
+	}
+
+	var lines []string
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, s := range active {
+		lines = append(lines, formatSilence(s))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🔔 Unmute #%s", s.ID), unmuteCallbackPrefix+s.ID),
+		))
+	}
+	notifier.SendTextWithKeyboard(chatID, "🔕 <b>Active silences:</b>\n\n"+strings.Join(lines, "\n"), tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows})
+}
+
+func handleMuteCallback(chatID int64, data string, from *tgbotapi.User, notifier notification.Notifier) {
+	parts := strings.SplitN(strings.TrimPrefix(data, alert.MuteCallbackPrefix), "_", 2)
+	if len(parts) < 2 {
+		return
+	}
+
+	duration, err := silence.ParseDuration(parts[0])
+	if err != nil {
+		notifier.SendText(chatID, "❌ "+utils.EscapeHTML(err.Error()))
+		return
+	}
+
+	container, err := docker.DockerClient.ContainerInspect(context.Background(), parts[1])
+	if err != nil {
+		notifier.SendText(chatID, "❌ Container not found, use <code>/mute &lt;container&gt; &lt;duration&gt;</code> instead")
+		return
+	}
+
+	s, err := silence.Add(strings.TrimPrefix(container.Name, "/"), duration, "", userName(from))
+	if err != nil {
+		notifier.SendText(chatID, "❌ Failed to mute: "+utils.EscapeHTML(err.Error()))
+		return
+	}
+	notifier.SendText(chatID, "🔕 "+formatSilence(s))
+}
+
+func formatSilence(s *silence.Silence) string {
+	expiry := "forever"
+	if !s.Forever() {
+		expiry = fmt.Sprintf("until %s (%s left)", s.ExpiresAt.Format("2006-01-02 15:04"), time.Until(s.ExpiresAt).Round(time.Minute))
+	}
+	text := fmt.Sprintf("<b>#%s</b> <u>%s</u> %s", s.ID, utils.EscapeHTML(s.Container), expiry)
+	if s.Pattern != "" {
+		text += fmt.Sprintf(", matching <code>%s</code>", utils.EscapeHTML(s.Pattern))
+	}
+	if s.CreatedBy != "" {
+		text += fmt.Sprintf(", by %s", utils.EscapeHTML(s.CreatedBy))
+	}
+	return text
+}
+
+func userName(user *tgbotapi.User) string {
+	if user == nil {
+		return ""
+	}
+	if user.UserName != "" {
+		return "@" + user.UserName
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
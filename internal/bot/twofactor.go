@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/audit"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/totp"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const totpIssuer = "Docker Monitor Bot"
+
+func handleTwoFactorCommand(chatID int64, msg *tgbotapi.Message, notifier notification.Notifier) {
+	if msg.From == nil {
+		return
+	}
+	userID := msg.From.ID
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 {
+		notifier.SendText(chatID, "Usage: <code>/2fa setup [current code]</code>, <code>/2fa &lt;code&gt;</code>, <code>/2fa status</code> or <code>/2fa disable &lt;code&gt;</code>")
+		return
+	}
+
+	switch args[0] {
+	case "setup":
+		if !msg.Chat.IsPrivate() {
+			notifier.SendText(chatID, "🔐 For security, run <code>/2fa setup</code> in a private chat with the bot")
+			return
+		}
+		var currentCode string
+		if len(args) > 1 {
+			currentCode = args[1]
+		}
+		secret, err := totp.Enroll(userID, currentCode)
+		if err != nil {
+			reportLockout(chatID, msg.From, err, notifier)
+			notifier.SendText(chatID, "❌ Failed to set up two-factor authentication: "+utils.EscapeHTML(err.Error()))
+			return
+		}
+		account := userName(msg.From)
+		if account == "" {
+			account = fmt.Sprintf("%d", userID)
+		}
+		notifier.SendText(chatID, fmt.Sprintf(
+			"🔐 <b>Two-factor authentication</b>\n\n"+
+				"Add this secret to your authenticator app:\n<code>%s</code>\n\n"+
+				"or use this URI:\n<code>%s</code>\n\n"+
+				"Then confirm the setup with <code>/2fa &lt;code&gt;</code>. "+
+				"If two-factor authentication was already enabled, the old secret stays active until the new one is confirmed.",
+			secret,
+			utils.EscapeHTML(totp.URI(totpIssuer, account, secret)),
+		))
+	case "status":
+		if !totp.Enrolled(userID) {
+			notifier.SendText(chatID, "🔓 Two-factor authentication is not set up")
+			return
+		}
+		if expiry, ok := totp.SessionExpiry(userID); ok {
+			notifier.SendText(chatID, fmt.Sprintf("🔐 Two-factor authentication is enabled, elevated until %s", expiry.Format("15:04:05")))
+			return
+		}
+		notifier.SendText(chatID, "🔐 Two-factor authentication is enabled, no elevated session")
+	case "disable":
+		if len(args) < 2 {
+			notifier.SendText(chatID, "Usage: <code>/2fa disable &lt;code&gt;</code>")
+			return
+		}
+		if err := totp.Verify(userID, args[1], botConfig.TOTPSessionLength); err != nil {
+			reportLockout(chatID, msg.From, err, notifier)
+			notifier.SendText(chatID, "❌ "+utils.EscapeHTML(err.Error()))
+			return
+		}
+		if err := totp.Disable(userID); err != nil {
+			notifier.SendText(chatID, "❌ Failed to disable two-factor authentication: "+utils.EscapeHTML(err.Error()))
+			return
+		}
+		notifier.SendText(chatID, "🔓 Two-factor authentication disabled")
+	default:
+		if err := totp.Verify(userID, args[0], botConfig.TOTPSessionLength); err != nil {
+			reportLockout(chatID, msg.From, err, notifier)
+			notifier.SendText(chatID, "❌ "+utils.EscapeHTML(err.Error()))
+			return
+		}
+		expiry, _ := totp.SessionExpiry(userID)
+		notifier.SendText(chatID, fmt.Sprintf("🔐 Elevated session for %s until %s", utils.EscapeHTML(userName(msg.From)), expiry.Format("15:04:05")))
+	}
+}
+
+func reportLockout(chatID int64, from *tgbotapi.User, err error, notifier notification.Notifier) {
+	var lockout *totp.LockoutError
+	if !errors.As(err, &lockout) || !lockout.New {
+		return
+	}
+	log.Printf("Two-factor authentication locked for user %d (%s) until %s", from.ID, userName(from), lockout.Until.Format(time.RFC3339))
+	entry := audit.Entry{
+		UserID: from.ID,
+		User:   userName(from),
+		ChatID: chatID,
+		Action: "2fa_lockout",
+		Result: audit.ResultFailed,
+		Error:  err.Error(),
+	}
+	if err := audit.Record(entry); err != nil {
+		log.Printf("Error writing audit entry: %v", err)
+	}
+
+	message := fmt.Sprintf(
+		"🔒 <b>Two-factor authentication locked</b>\n\n"+
+			"<pre>"+
+			"┌ User: %s (%d)\n"+
+			"├ Chat: %d\n"+
+			"└ Locked until: %s"+
+			"</pre>\n\n"+
+			"Too many invalid codes were entered.",
+		utils.EscapeHTML(userName(from)),
+		from.ID,
+		chatID,
+		lockout.Until.Format("2006-01-02 15:04:05"),
+	)
+	notifyAdmins(chatID, message, notifier)
+}
+
+func requireElevation(chatID int64, action string, from *tgbotapi.User, notifier notification.Notifier) bool {
+	required := false
+	for _, totpAction := range botConfig.TOTPActions {
+		if totpAction == action {
+			required = true
+			break
+		}
+	}
+	if !required {
+		return true
+	}
+	if from == nil {
+		return false
+	}
+	if _, ok := totp.SessionExpiry(from.ID); ok {
+		return true
+	}
+
+	if !totp.Enrolled(from.ID) {
+		notifier.SendText(chatID, fmt.Sprintf("🔐 <i>%s</i> requires two-factor authentication. Set it up with <code>/2fa setup</code> in a private chat with the bot first.", action))
+		return false
+	}
+	notifier.SendText(chatID, fmt.Sprintf("🔐 <i>%s</i> requires two-factor authentication. Send <code>/2fa &lt;code&gt;</code> and try again.", action))
+	return false
+}
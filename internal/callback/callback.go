@@ -0,0 +1,189 @@
+package callback
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	Prefix = "s:"
+
+	version       = 1
+	containerSize = 6
+	macSize       = 10
+	payloadSize   = 1 + 1 + 2 + 4 + 8 + 4 + containerSize
+)
+
+// Action codes are part of the encoded payload, so new actions must only be
+// appended to keep buttons sent by older versions of the bot working.
+var actions = []string{
+	"",
+	"open",
+	"page",
+	"start",
+	"stop",
+	"restart",
+	"mute_1h",
+	"mute_24h",
+	"mute_forever",
+	"pause",
+	"unpause",
+	"kill",
+	"kill_SIGTERM",
+	"kill_SIGKILL",
+	"kill_SIGHUP",
+	"kill_SIGUSR1",
+	"remove",
+	"remove_container",
+	"remove_volumes",
+	"exec",
+	"logs_follow",
+	"logs_stop",
+	"logs_download",
+	"alert_all",
+	"alert_ctx",
+	"alert_dl",
+	"alert_open",
+	"ack",
+}
+
+var (
+	ErrInvalid   = errors.New("invalid button")
+	ErrExpired   = errors.New("button expired")
+	ErrWrongChat = errors.New("button belongs to another chat")
+)
+
+type Payload struct {
+	Action      string
+	ContainerID string
+	Page        int
+	Arg         uint32
+	ChatID      int64
+	ExpiresAt   time.Time
+}
+
+var (
+	secret []byte
+	ttl    = 7 * 24 * time.Hour
+)
+
+func Init(key string, lifetime time.Duration) {
+	secret = []byte(key)
+	if lifetime > 0 {
+		ttl = lifetime
+	}
+}
+
+func New(action, containerID string, page int, chatID int64) string {
+	return encodeOrInvalid(Payload{
+		Action:      action,
+		ContainerID: containerID,
+		Page:        page,
+		ChatID:      chatID,
+		ExpiresAt:   time.Now().Add(ttl),
+	})
+}
+
+func NewWithArg(action, containerID string, arg uint32, chatID int64) string {
+	return encodeOrInvalid(Payload{
+		Action:      action,
+		ContainerID: containerID,
+		Arg:         arg,
+		ChatID:      chatID,
+		ExpiresAt:   time.Now().Add(ttl),
+	})
+}
+
+func encodeOrInvalid(p Payload) string {
+	data, err := Encode(p)
+	if err != nil {
+		log.Printf("Error encoding button: %v", err)
+		return Prefix + "invalid"
+	}
+	return data
+}
+
+func Encode(p Payload) (string, error) {
+	code := actionCode(p.Action)
+	if code < 0 {
+		return "", fmt.Errorf("unknown callback action %q", p.Action)
+	}
+
+	buf := make([]byte, payloadSize, payloadSize+macSize)
+	buf[0] = version
+	buf[1] = byte(code)
+	binary.BigEndian.PutUint16(buf[2:4], uint16(p.Page))
+	binary.BigEndian.PutUint32(buf[4:8], p.Arg)
+	binary.BigEndian.PutUint64(buf[8:16], uint64(p.ChatID))
+	binary.BigEndian.PutUint32(buf[16:20], uint32(p.ExpiresAt.Unix()))
+	if len(p.ContainerID) >= containerSize*2 {
+		hex.Decode(buf[20:20+containerSize], []byte(p.ContainerID[:containerSize*2]))
+	}
+	buf = append(buf, sign(buf)...)
+	return Prefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func Decode(data string, chatID int64) (Payload, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(data, Prefix))
+	if err != nil || len(raw) != payloadSize+macSize || raw[0] != version {
+		return Payload{}, ErrInvalid
+	}
+	if !hmac.Equal(raw[payloadSize:], sign(raw[:payloadSize])) {
+		return Payload{}, ErrInvalid
+	}
+	code := int(raw[1])
+	if code == 0 || code >= len(actions) {
+		return Payload{}, ErrInvalid
+	}
+
+	p := Payload{
+		Action:    actions[code],
+		Page:      int(binary.BigEndian.Uint16(raw[2:4])),
+		Arg:       binary.BigEndian.Uint32(raw[4:8]),
+		ChatID:    int64(binary.BigEndian.Uint64(raw[8:16])),
+		ExpiresAt: time.Unix(int64(binary.BigEndian.Uint32(raw[16:20])), 0),
+	}
+	if container := raw[20:payloadSize]; !allZero(container) {
+		p.ContainerID = hex.EncodeToString(container)
+	}
+
+	if time.Now().After(p.ExpiresAt) {
+		return p, ErrExpired
+	}
+	if p.ChatID != 0 && p.ChatID != chatID {
+		return p, ErrWrongChat
+	}
+	return p, nil
+}
+
+func sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)[:macSize]
+}
+
+func actionCode(action string) int {
+	for i, name := range actions {
+		if i > 0 && name == action {
+			return i
+		}
+	}
+	return -1
+}
+
+func allZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package callback
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const containerID = "4f3c2a1b9d8e7f6051a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708"
+
+func TestEncodeDecode(t *testing.T) {
+	Init("test-secret", time.Hour)
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	tests := []Payload{
+		{Action: "open", ContainerID: containerID, Page: 3, ChatID: -1001234567890},
+		{Action: "page", Page: 65535, ChatID: 42},
+		{Action: "exec", ContainerID: containerID, Arg: 4294967295, ChatID: 42},
+		{Action: "logs_follow", ContainerID: containerID, Arg: 1440, ChatID: 42},
+		{Action: "ack", ContainerID: containerID, Arg: 1714564800},
+		{Action: "mute_forever", ContainerID: containerID},
+	}
+	for _, want := range tests {
+		t.Run(want.Action, func(t *testing.T) {
+			want.ExpiresAt = expires
+			data, err := Encode(want)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if !strings.HasPrefix(data, Prefix) || len(data) > 64 {
+				t.Fatalf("Encode = %q (%d bytes), want a %q prefix and at most 64 bytes", data, len(data), Prefix)
+			}
+			got, err := Decode(data, 42)
+			if want.ChatID != 0 && want.ChatID != 42 {
+				got, err = Decode(data, want.ChatID)
+			}
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			want.ContainerID = shortContainer(want.ContainerID)
+			if got != want {
+				t.Errorf("Decode = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	Init("test-secret", time.Hour)
+	valid, err := Encode(Payload{Action: "stop", ContainerID: containerID, ChatID: 42, ExpiresAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	expired, _ := Encode(Payload{Action: "stop", ContainerID: containerID, ChatID: 42, ExpiresAt: time.Now().Add(-time.Minute)})
+
+	raw, _ := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(valid, Prefix))
+	tamper := func(i int) string {
+		changed := append([]byte(nil), raw...)
+		changed[i] ^= 0x01
+		return Prefix + base64.RawURLEncoding.EncodeToString(changed)
+	}
+
+	tests := []struct {
+		name   string
+		data   string
+		chatID int64
+		want   error
+	}{
+		{"valid", valid, 42, nil},
+		{"wrong chat", valid, 43, ErrWrongChat},
+		{"expired", expired, 42, ErrExpired},
+		{"tampered action", tamper(1), 42, ErrInvalid},
+		{"tampered chat", tamper(15), 42, ErrInvalid},
+		{"tampered container", tamper(payloadSize - 1), 42, ErrInvalid},
+		{"tampered signature", tamper(len(raw) - 1), 42, ErrInvalid},
+		{"truncated", valid[:len(valid)-4], 42, ErrInvalid},
+		{"not base64", Prefix + "!!!", 42, ErrInvalid},
+		{"empty", Prefix, 42, ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.data, tt.chatID); !errors.Is(err, tt.want) {
+				t.Errorf("Decode error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	Init("other-secret", time.Hour)
+	defer Init("test-secret", time.Hour)
+	if _, err := Decode(valid, 42); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Decode with another secret error = %v, want %v", err, ErrInvalid)
+	}
+}
+
+func TestEncodeUnknownAction(t *testing.T) {
+	Init("test-secret", time.Hour)
+	if _, err := Encode(Payload{Action: "explode"}); err == nil {
+		t.Error("Encode accepted an unknown action")
+	}
+	if data := New("explode", containerID, 0, 42); !errors.Is(decodeError(data), ErrInvalid) {
+		t.Errorf("New with an unknown action = %q, want an invalid button", data)
+	}
+}
+
+func shortContainer(id string) string {
+	if len(id) < containerSize*2 {
+		return id
+	}
+	return id[:containerSize*2]
+}
+
+func decodeError(data string) error {
+	_, err := Decode(data, 42)
+	return err
+}
@@ -4,18 +4,63 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	LogModeFollow = "follow"
+	LogModePoll   = "poll"
+)
+
 type Config struct {
 	TelegramBotToken string
 	TelegramChatID   int64
+	AccessChats      map[int64]string
+	AccessUsers      map[int64]string
+	CallbackSecret   string
+	CallbackTTL      time.Duration
 	DockerHost       string
 	PollInterval     time.Duration
 	TailCount        int
+	LogMode          string
 	Language         string
+	StateDir         string
+
+	CrashLoopThreshold int
+	CrashLoopWindow    time.Duration
+
+	AutohealMaxAttempts int
+	AutohealBackoff     time.Duration
+
+	StderrIsError bool
+	RulesFile     string
+
+	JSONLevelFields   []string
+	JSONMessageFields []string
+	JSONDisplayFields []string
+
+	ErrorCooldown     time.Duration
+	AlertContextLines int
+
+	StopTimeout time.Duration
+
+	ExecCommandsFile string
+	ExecTimeout      time.Duration
+
+	LogsFollowDuration time.Duration
+
+	ConfirmActions      []string
+	ConfirmationTimeout time.Duration
+
+	TOTPActions       []string
+	TOTPSessionLength time.Duration
+
+	EscalationInterval   time.Duration
+	EscalationMaxResends int
+	EscalationChatID     int64
 }
 
 func LoadConfig() (*Config, error) {
@@ -37,6 +82,49 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("invalid TELEGRAM_CHAT_ID format: %v", err)
 	}
 
+	chatRole := os.Getenv("TELEGRAM_CHAT_ROLE")
+	if chatRole == "" {
+		chatRole = "viewer"
+	}
+	accessChats := map[int64]string{chatID: chatRole}
+	for _, entry := range listFromEnv("ALLOWED_CHATS", nil) {
+		idStr, role, found := strings.Cut(entry, ":")
+		if !found {
+			role = "viewer"
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ALLOWED_CHATS entry %q: %v", entry, err)
+		}
+		accessChats[id] = role
+	}
+
+	accessUsers := make(map[int64]string)
+	for _, role := range []string{"viewer", "operator", "admin"} {
+		key := strings.ToUpper(role) + "_USER_IDS"
+		for _, idStr := range listFromEnv(key, nil) {
+			id, err := strconv.ParseInt(idStr, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s entry %q: %v", key, idStr, err)
+			}
+			accessUsers[id] = role
+		}
+	}
+	if _, configured := accessUsers[chatID]; chatID > 0 && !configured {
+		accessUsers[chatID] = "admin"
+	}
+
+	escalationInterval := time.Duration(intFromEnv("ESCALATION_INTERVAL_MINUTES", 15)) * time.Minute
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("ESCALATION_INTERVAL_MINUTES"))) {
+	case "0", "off", "none", "false":
+		escalationInterval = 0
+	}
+
+	callbackSecret := os.Getenv("CALLBACK_SECRET")
+	if callbackSecret == "" {
+		callbackSecret = botToken
+	}
+
 	pollIntervalStr := os.Getenv("POLL_INTERVAL_SECONDS")
 	var pollInterval time.Duration
 	if pollIntervalStr == "" {
@@ -56,22 +144,113 @@ func LoadConfig() (*Config, error) {
 		tailCount = 100
 	}
 
+	logMode := os.Getenv("LOG_MODE")
+	if logMode != LogModePoll {
+		logMode = LogModeFollow
+	}
+
 	lang := os.Getenv("LANGUAGE")
 	if lang == "" {
 		lang = "en"
 	}
 
+	stateDir := os.Getenv("STATE_DIR")
+	if stateDir == "" {
+		stateDir = "data"
+	}
+
 	dockerHost := os.Getenv("DOCKER_HOST")
 	if dockerHost == "" {
 		dockerHost = "unix:///var/run/docker.sock"
 	}
 
+	crashLoopThreshold := intFromEnv("CRASH_LOOP_THRESHOLD", 3)
+	crashLoopWindow := time.Duration(intFromEnv("CRASH_LOOP_WINDOW_MINUTES", 5)) * time.Minute
+
+	autohealMaxAttempts := intFromEnv("AUTOHEAL_MAX_ATTEMPTS_PER_HOUR", 5)
+	autohealBackoff := time.Duration(intFromEnv("AUTOHEAL_BACKOFF_SECONDS", 10)) * time.Second
+
+	stderrIsError, _ := strconv.ParseBool(os.Getenv("LOG_STDERR_IS_ERROR"))
+
+	confirmActions := listFromEnv("CONFIRM_ACTIONS", []string{"stop", "restart", "kill", "remove"})
+	if len(confirmActions) == 1 && confirmActions[0] == "none" {
+		confirmActions = nil
+	}
+
+	var escalationChatID int64
+	if escalationChatIDStr := os.Getenv("ESCALATION_CHAT_ID"); escalationChatIDStr != "" {
+		escalationChatID, err = strconv.ParseInt(escalationChatIDStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ESCALATION_CHAT_ID format: %v", err)
+		}
+	}
+
 	return &Config{
 		TelegramBotToken: botToken,
 		TelegramChatID:   chatID,
+		AccessChats:      accessChats,
+		AccessUsers:      accessUsers,
+		CallbackSecret:   callbackSecret,
+		CallbackTTL:      time.Duration(intFromEnv("CALLBACK_TTL_HOURS", 168)) * time.Hour,
 		DockerHost:       dockerHost,
 		PollInterval:     pollInterval,
 		TailCount:        tailCount,
+		LogMode:          logMode,
 		Language:         lang,
+		StateDir:         stateDir,
+
+		CrashLoopThreshold: crashLoopThreshold,
+		CrashLoopWindow:    crashLoopWindow,
+
+		AutohealMaxAttempts: autohealMaxAttempts,
+		AutohealBackoff:     autohealBackoff,
+
+		StderrIsError: stderrIsError,
+		RulesFile:     os.Getenv("RULES_FILE"),
+
+		JSONLevelFields:   listFromEnv("LOG_JSON_LEVEL_FIELDS", []string{"level", "severity", "lvl"}),
+		JSONMessageFields: listFromEnv("LOG_JSON_MESSAGE_FIELDS", []string{"msg", "message"}),
+		JSONDisplayFields: listFromEnv("LOG_JSON_DISPLAY_FIELDS", nil),
+
+		ErrorCooldown:     time.Duration(intFromEnv("ERROR_COOLDOWN_MINUTES", 10)) * time.Minute,
+		AlertContextLines: intFromEnv("ALERT_CONTEXT_LINES", 5),
+
+		StopTimeout: time.Duration(intFromEnv("STOP_TIMEOUT_SECONDS", 10)) * time.Second,
+
+		ExecCommandsFile: os.Getenv("EXEC_COMMANDS_FILE"),
+		ExecTimeout:      time.Duration(intFromEnv("EXEC_TIMEOUT_SECONDS", 60)) * time.Second,
+
+		LogsFollowDuration: time.Duration(intFromEnv("LOGS_FOLLOW_MINUTES", 5)) * time.Minute,
+
+		ConfirmActions:      confirmActions,
+		ConfirmationTimeout: time.Duration(intFromEnv("CONFIRMATION_TIMEOUT_SECONDS", 60)) * time.Second,
+
+		TOTPActions:       listFromEnv("TOTP_REQUIRED_ACTIONS", nil),
+		TOTPSessionLength: time.Duration(intFromEnv("TOTP_SESSION_MINUTES", 15)) * time.Minute,
+
+		EscalationInterval:   escalationInterval,
+		EscalationMaxResends: intFromEnv("ESCALATION_MAX_RESENDS", 3),
+		EscalationChatID:     escalationChatID,
 	}, nil
 }
+
+func intFromEnv(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func listFromEnv(key string, defaultValue []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
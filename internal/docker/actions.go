@@ -0,0 +1,25 @@
+package docker
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+const StopTimeoutLabel = "docker-monitor.stop-timeout"
+
+var KillSignals = []string{"SIGTERM", "SIGKILL", "SIGHUP", "SIGUSR1"}
+
+func StopTimeout(container types.ContainerJSON, fallback time.Duration) time.Duration {
+	if container.Config == nil {
+		return fallback
+	}
+	if seconds, err := strconv.Atoi(container.Config.Labels[StopTimeoutLabel]); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if container.Config.StopTimeout != nil && *container.Config.StopTimeout >= 0 {
+		return time.Duration(*container.Config.StopTimeout) * time.Second
+	}
+	return fallback
+}
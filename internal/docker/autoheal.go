@@ -0,0 +1,194 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/incident"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const (
+	autohealLabel    = "docker-monitor.autoheal"
+	autohealWindow   = time.Hour
+	autohealMaxDelay = 5 * time.Minute
+
+	healReasonUnhealthy = "unhealthy"
+	healReasonExit      = "exited with code %s"
+)
+
+type healHistory struct {
+	Attempts          []time.Time
+	Pending           bool
+	ExhaustedNotified bool
+}
+
+type autoHealer struct {
+	mu          sync.Mutex
+	chatID      int64
+	notifier    notification.Notifier
+	maxAttempts int
+	baseDelay   time.Duration
+	stopTimeout time.Duration
+	containers  map[string]*healHistory
+}
+
+func newAutoHealer(chatID int64, notifier notification.Notifier, maxAttempts int, baseDelay, stopTimeout time.Duration) *autoHealer {
+	return &autoHealer{
+		chatID:      chatID,
+		notifier:    notifier,
+		maxAttempts: maxAttempts,
+		baseDelay:   baseDelay,
+		stopTimeout: stopTimeout,
+		containers:  make(map[string]*healHistory),
+	}
+}
+
+func autohealEnabled(event events.Message) bool {
+	enabled, err := strconv.ParseBool(event.Actor.Attributes[autohealLabel])
+	return err == nil && enabled
+}
+
+func (h *autoHealer) trigger(ctx context.Context, id, name, reason string) {
+	h.mu.Lock()
+	history, ok := h.containers[id]
+	if !ok {
+		history = &healHistory{}
+		h.containers[id] = history
+	}
+	if history.Pending {
+		h.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	var recent []time.Time
+	for _, attempt := range history.Attempts {
+		if now.Sub(attempt) < autohealWindow {
+			recent = append(recent, attempt)
+		}
+	}
+	history.Attempts = recent
+	if len(recent) == 0 {
+		history.ExhaustedNotified = false
+	}
+
+	if len(recent) >= h.maxAttempts {
+		notify := !history.ExhaustedNotified
+		history.ExhaustedNotified = true
+		h.mu.Unlock()
+
+		if notify {
+			log.Printf("Auto-heal budget exhausted for container %s", name)
+			message := fmt.Sprintf(
+				"🛑 <b>Auto-heal budget exhausted for <u>%s</u></b>\n\n"+
+					"<pre>"+
+					"┌ ID: %s\n"+
+					"├ Reason: %s\n"+
+					"└ Attempts: %d in the last hour"+
+					"</pre>\n\n"+
+					"Manual intervention is required.",
+				name,
+				id[:12],
+				reason,
+				len(recent),
+			)
+			sendContainerAlert(h.notifier, h.chatID, id, name, "autoheal", message, true)
+		}
+		return
+	}
+
+	attempt := len(recent) + 1
+	delay := h.baseDelay << (attempt - 1)
+	if delay > autohealMaxDelay || delay <= 0 {
+		delay = autohealMaxDelay
+	}
+	history.Attempts = append(history.Attempts, now)
+	history.Pending = true
+	h.mu.Unlock()
+
+	go h.heal(ctx, id, name, reason, attempt, delay)
+}
+
+func (h *autoHealer) heal(ctx context.Context, id, name, reason string, attempt int, delay time.Duration) {
+	defer func() {
+		h.mu.Lock()
+		if history, ok := h.containers[id]; ok {
+			history.Pending = false
+		}
+		h.mu.Unlock()
+	}()
+
+	if !sleepContext(ctx, delay) {
+		return
+	}
+
+	container, err := DockerClient.ContainerInspect(ctx, id)
+	if err != nil {
+		log.Printf("Auto-heal: error inspecting container %s: %v", name, err)
+		return
+	}
+	if recovered(container.State) {
+		log.Printf("Auto-heal: container %s recovered on its own, skipping restart", name)
+		return
+	}
+	if reason != healReasonUnhealthy && managedByRestartPolicy(container) {
+		log.Printf("Auto-heal: container %s has a restart policy, leaving it to Docker", name)
+		return
+	}
+
+	timeout := StopTimeout(container, h.stopTimeout)
+	err = DockerClient.ContainerRestart(ctx, id, &timeout)
+
+	result := "✅ Restarted"
+	detail := fmt.Sprintf("Auto-heal restart %d/%d (%s)", attempt, h.maxAttempts, reason)
+	if err != nil {
+		log.Printf("Auto-heal: failed to restart container %s: %v", name, err)
+		result = "❌ " + utils.EscapeHTML(err.Error())
+		detail += ": " + err.Error()
+	} else {
+		log.Printf("Auto-heal: restarted container %s (attempt %d/%d)", name, attempt, h.maxAttempts)
+	}
+	recordIncident(id, name, incident.KindRestart, detail, time.Now())
+
+	message := fmt.Sprintf(
+		"🩹 <b>Auto-heal: <u>%s</u></b>\n\n"+
+			"<pre>"+
+			"┌ ID: %s\n"+
+			"├ Reason: %s\n"+
+			"├ Attempt: %d/%d (after %s)\n"+
+			"└ Result: %s"+
+			"</pre>",
+		name,
+		id[:12],
+		reason,
+		attempt,
+		h.maxAttempts,
+		delay,
+		result,
+	)
+	sendContainerAlert(h.notifier, h.chatID, id, name, "autoheal", message, false)
+}
+
+func recovered(state *types.ContainerState) bool {
+	if state == nil || !state.Running || state.Restarting {
+		return false
+	}
+	return state.Health == nil || state.Health.Status != types.Unhealthy
+}
+
+func managedByRestartPolicy(container types.ContainerJSON) bool {
+	if container.HostConfig == nil {
+		return false
+	}
+	policy := container.HostConfig.RestartPolicy
+	return !policy.IsNone() && policy.Name != ""
+}
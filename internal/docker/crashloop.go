@@ -0,0 +1,115 @@
+package docker
+
+import (
+	"sync"
+	"time"
+)
+
+type crashRecord struct {
+	At       time.Time
+	ExitCode string
+}
+
+type crashHistory struct {
+	Name      string
+	Dies      []crashRecord
+	Looping   bool
+	LoopStart time.Time
+	LoopDies  int
+}
+
+type crashLoopState int
+
+const (
+	crashLoopNone crashLoopState = iota
+	crashLoopStarted
+	crashLoopOngoing
+)
+
+type endedCrashLoop struct {
+	ID       string
+	Name     string
+	Dies     int
+	Duration time.Duration
+}
+
+type crashLoopTracker struct {
+	mu         sync.Mutex
+	threshold  int
+	window     time.Duration
+	containers map[string]*crashHistory
+}
+
+func newCrashLoopTracker(threshold int, window time.Duration) *crashLoopTracker {
+	return &crashLoopTracker{
+		threshold:  threshold,
+		window:     window,
+		containers: make(map[string]*crashHistory),
+	}
+}
+
+func (t *crashLoopTracker) recordDie(id, name, exitCode string, at time.Time) (crashLoopState, []crashRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	history, ok := t.containers[id]
+	if !ok {
+		history = &crashHistory{}
+		t.containers[id] = history
+	}
+	history.Name = name
+	history.Dies = append(pruneCrashes(history.Dies, at.Add(-t.window)), crashRecord{At: at, ExitCode: exitCode})
+
+	if history.Looping {
+		history.LoopDies++
+		return crashLoopOngoing, append([]crashRecord(nil), history.Dies...)
+	}
+	if len(history.Dies) >= t.threshold {
+		history.Looping = true
+		history.LoopStart = history.Dies[0].At
+		history.LoopDies = len(history.Dies)
+		return crashLoopStarted, append([]crashRecord(nil), history.Dies...)
+	}
+	return crashLoopNone, nil
+}
+
+func (t *crashLoopTracker) isLooping(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	history, ok := t.containers[id]
+	return ok && history.Looping
+}
+
+func (t *crashLoopTracker) sweep(now time.Time) []endedCrashLoop {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var ended []endedCrashLoop
+	for id, history := range t.containers {
+		history.Dies = pruneCrashes(history.Dies, now.Add(-t.window))
+		if len(history.Dies) > 0 {
+			continue
+		}
+		if history.Looping {
+			ended = append(ended, endedCrashLoop{
+				ID:       id,
+				Name:     history.Name,
+				Dies:     history.LoopDies,
+				Duration: now.Sub(history.LoopStart),
+			})
+		}
+		delete(t.containers, id)
+	}
+	return ended
+}
+
+func pruneCrashes(records []crashRecord, cutoff time.Time) []crashRecord {
+	kept := records[:0]
+	for _, record := range records {
+		if record.At.After(cutoff) {
+			kept = append(kept, record)
+		}
+	}
+	return kept
+}
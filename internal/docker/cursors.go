@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/storage"
+)
+
+const (
+	cursorsFileName      = "log_cursors.json"
+	cursorsFlushInterval = 5 * time.Second
+)
+
+type cursorStore struct {
+	mu      sync.Mutex
+	path    string
+	cursors map[string]time.Time
+	dirty   bool
+}
+
+func loadCursorStore(stateDir string) *cursorStore {
+	store := &cursorStore{
+		path:    filepath.Join(stateDir, cursorsFileName),
+		cursors: make(map[string]time.Time),
+	}
+	if err := storage.LoadJSON(store.path, &store.cursors); err != nil {
+		log.Printf("Error loading log cursors, starting from scratch: %v", err)
+		store.cursors = make(map[string]time.Time)
+	}
+	return store
+}
+
+func (s *cursorStore) get(containerID string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cursor, ok := s.cursors[containerID]
+	return cursor, ok
+}
+
+func (s *cursorStore) advance(containerID string, to time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if to.After(s.cursors[containerID]) {
+		s.cursors[containerID] = to
+		s.dirty = true
+	}
+}
+
+func (s *cursorStore) forget(containerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.cursors[containerID]; ok {
+		delete(s.cursors, containerID)
+		s.dirty = true
+	}
+}
+
+func (s *cursorStore) retain(containerIDs map[string]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range s.cursors {
+		if !containerIDs[id] {
+			delete(s.cursors, id)
+			s.dirty = true
+		}
+	}
+}
+
+func (s *cursorStore) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return
+	}
+	if err := storage.SaveJSON(s.path, s.cursors); err != nil {
+		log.Printf("Error saving log cursors: %v", err)
+		return
+	}
+	s.dirty = false
+}
+
+func (s *cursorStore) run(ctx context.Context) {
+	ticker := time.NewTicker(cursorsFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flush()
+		case <-ctx.Done():
+			s.flush()
+			return
+		}
+	}
+}
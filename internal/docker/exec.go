@@ -0,0 +1,126 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/storage"
+)
+
+const execLabelPrefix = "docker-monitor.exec."
+
+type ExecCommand struct {
+	Name string
+	Cmd  []string
+}
+
+var execCommands map[string]map[string][]string
+
+func InitExecCommands(filePath string) error {
+	if filePath == "" {
+		return nil
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		return fmt.Errorf("failed to read %s: %v", filePath, err)
+	}
+	var commands map[string]map[string][]string
+	if err := storage.LoadJSON(filePath, &commands); err != nil {
+		return err
+	}
+	for container, named := range commands {
+		if _, err := path.Match(container, ""); err != nil {
+			return fmt.Errorf("invalid container pattern %q in %s", container, filePath)
+		}
+		for name, cmd := range named {
+			if len(cmd) == 0 {
+				return fmt.Errorf("command %q for %q in %s is empty", name, container, filePath)
+			}
+		}
+	}
+	execCommands = commands
+	return nil
+}
+
+func ExecCommandsFor(container types.ContainerJSON) []ExecCommand {
+	name := strings.TrimPrefix(container.Name, "/")
+	merged := make(map[string][]string)
+	for pattern, named := range execCommands {
+		if ok, _ := path.Match(pattern, name); !ok {
+			continue
+		}
+		for commandName, cmd := range named {
+			merged[commandName] = cmd
+		}
+	}
+	if container.Config != nil {
+		for label, value := range container.Config.Labels {
+			if !strings.HasPrefix(label, execLabelPrefix) {
+				continue
+			}
+			if cmd := parseExecLabel(value); len(cmd) > 0 {
+				merged[strings.TrimPrefix(label, execLabelPrefix)] = cmd
+			}
+		}
+	}
+
+	commands := make([]ExecCommand, 0, len(merged))
+	for commandName, cmd := range merged {
+		commands = append(commands, ExecCommand{Name: commandName, Cmd: cmd})
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	return commands
+}
+
+func parseExecLabel(value string) []string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") {
+		var cmd []string
+		if err := json.Unmarshal([]byte(value), &cmd); err == nil {
+			return cmd
+		}
+	}
+	return strings.Fields(value)
+}
+
+func Exec(ctx context.Context, containerID string, cmd []string, handle func(LogLine)) (int, error) {
+	created, err := DockerClient.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create exec: %v", err)
+	}
+
+	attached, err := DockerClient.ContainerExecAttach(ctx, created.ID, types.ExecStartCheck{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to start exec: %v", err)
+	}
+	defer attached.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ReadLogLines(attached.Reader, false, false, handle)
+	}()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read exec output: %v", err)
+	}
+
+	inspect, err := DockerClient.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect exec: %v", err)
+	}
+	return inspect.ExitCode, nil
+}
@@ -0,0 +1,132 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const exitLogLines = 10
+
+type exitDetails struct {
+	ExitCode     int
+	OOMKilled    bool
+	Error        string
+	RestartCount int
+	Uptime       time.Duration
+	LastLines    []string
+}
+
+func inspectExit(ctx context.Context, containerID string) (*exitDetails, error) {
+	container, err := DockerClient.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &exitDetails{
+		ExitCode:     container.State.ExitCode,
+		OOMKilled:    container.State.OOMKilled,
+		Error:        container.State.Error,
+		RestartCount: container.RestartCount,
+	}
+
+	startedAt, startErr := time.Parse(time.RFC3339Nano, container.State.StartedAt)
+	finishedAt, finishErr := time.Parse(time.RFC3339Nano, container.State.FinishedAt)
+	if startErr == nil && finishErr == nil && finishedAt.After(startedAt) {
+		details.Uptime = finishedAt.Sub(startedAt)
+	}
+
+	lines, err := fetchLastLogLines(ctx, containerID, container.Config.Tty, exitLogLines)
+	if err == nil {
+		details.LastLines = lines
+	}
+	return details, nil
+}
+
+func fetchLastLogLines(ctx context.Context, containerID string, tty bool, count int) ([]string, error) {
+	out, err := DockerClient.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       fmt.Sprintf("%d", count),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+
+	var lines []string
+	err = ReadLogLines(out, tty, false, func(line LogLine) {
+		lines = append(lines, line.Text)
+	})
+	return lines, err
+}
+
+func describeExitCode(code int) string {
+	switch code {
+	case 0:
+		return "exited normally"
+	case 1:
+		return "application error"
+	case 2:
+		return "misuse of shell builtin"
+	case 125:
+		return "container failed to run"
+	case 126:
+		return "command cannot be invoked"
+	case 127:
+		return "command not found"
+	case 130:
+		return "interrupted (SIGINT)"
+	case 134:
+		return "aborted (SIGABRT)"
+	case 137:
+		return "killed (SIGKILL), often out of memory or docker kill"
+	case 139:
+		return "segmentation fault (SIGSEGV)"
+	case 143:
+		return "terminated (SIGTERM), graceful stop"
+	}
+	if code > 128 && code < 160 {
+		return fmt.Sprintf("killed by signal %d", code-128)
+	}
+	return "application error"
+}
+
+func formatExitDetails(details *exitDetails) string {
+	oomKilled := "no"
+	if details.OOMKilled {
+		oomKilled = "yes"
+	}
+
+	uptime := "unknown"
+	if details.Uptime > 0 {
+		uptime = details.Uptime.Round(time.Second).String()
+	}
+
+	lines := []string{
+		fmt.Sprintf("├ Exit code: %d (%s)", details.ExitCode, describeExitCode(details.ExitCode)),
+		fmt.Sprintf("├ OOM killed: %s", oomKilled),
+	}
+	if details.Error != "" {
+		lines = append(lines, fmt.Sprintf("├ Error: %s", utils.EscapeHTML(details.Error)))
+	}
+	lines = append(lines,
+		fmt.Sprintf("├ Restarts: %d", details.RestartCount),
+		fmt.Sprintf("└ Uptime: %s", uptime),
+	)
+	return strings.Join(lines, "\n")
+}
+
+func formatLogLines(lines []string) string {
+	var escaped []string
+	for _, line := range lines {
+		filtered := utils.RemoveControlCharactersRegex(strings.ToValidUTF8(line, ""))
+		escaped = append(escaped, utils.EscapeHTML(utils.Truncate(filtered, 200)))
+	}
+	return strings.Join(escaped, "\n")
+}
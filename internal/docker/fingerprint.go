@@ -0,0 +1,215 @@
+package docker
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/storage"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const (
+	fingerprintsFileName  = "error_fingerprints.json"
+	fingerprintsRetention = 30 * 24 * time.Hour
+	maxFingerprints       = 5000
+)
+
+var fingerprintNormalizers = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), "<ts>"},
+	{regexp.MustCompile(`\b\d{2}:\d{2}:\d{2}(\.\d+)?\b`), "<time>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`), "<ip>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{1,4}(::?[0-9a-f]{1,4}){2,7}\b`), "<ip>"},
+	{regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b`), "<hex>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{12,}\b`), "<hex>"},
+	{regexp.MustCompile(`\d+`), "<n>"},
+	{regexp.MustCompile(`\s+`), " "},
+}
+
+func normalizeErrorText(text string) string {
+	for _, normalizer := range fingerprintNormalizers {
+		text = normalizer.re.ReplaceAllString(text, normalizer.replacement)
+	}
+	return strings.TrimSpace(text)
+}
+
+func errorFingerprint(rule, text string) string {
+	return utils.HashString(rule + "\x00" + normalizeErrorText(text))
+}
+
+type fingerprintStats struct {
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	LastAlerted time.Time `json:"last_alerted"`
+	Total       int       `json:"total"`
+	SinceAlert  int       `json:"since_alert"`
+	ContainerID string    `json:"container_id,omitempty"`
+	Container   string    `json:"container,omitempty"`
+	Rule        string    `json:"rule,omitempty"`
+	Severity    string    `json:"severity,omitempty"`
+	Sample      string    `json:"sample,omitempty"`
+}
+
+type fingerprintSummary struct {
+	ContainerID string
+	Container   string
+	Rule        string
+	Severity    string
+	Sample      string
+	Repeats     int
+	Window      time.Duration
+}
+
+type fingerprintVerdict struct {
+	New        bool
+	Suppressed bool
+	Repeats    int
+	Window     time.Duration
+}
+
+type fingerprintStore struct {
+	mu       sync.Mutex
+	path     string
+	cooldown time.Duration
+	entries  map[string]*fingerprintStats
+	dirty    bool
+}
+
+func loadFingerprintStore(stateDir string, cooldown time.Duration) *fingerprintStore {
+	store := &fingerprintStore{
+		path:     filepath.Join(stateDir, fingerprintsFileName),
+		cooldown: cooldown,
+		entries:  make(map[string]*fingerprintStats),
+	}
+	if err := storage.LoadJSON(store.path, &store.entries); err != nil {
+		log.Printf("Error loading error fingerprints, starting from scratch: %v", err)
+		store.entries = make(map[string]*fingerprintStats)
+	}
+
+	store.pruneLocked(time.Now())
+	return store
+}
+
+func (s *fingerprintStore) observe(info containerInfo, fingerprint, rule, severity, sample string, now time.Time) fingerprintVerdict {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := info.Name + "/" + fingerprint
+	s.dirty = true
+
+	stats, ok := s.entries[key]
+	if !ok {
+		stats = &fingerprintStats{FirstSeen: now, LastSeen: now, LastAlerted: now}
+		s.entries[key] = stats
+	}
+	stats.ContainerID, stats.Container = info.ID, info.Name
+	stats.Rule, stats.Severity = rule, severity
+	stats.Sample = utils.Truncate(sample, 300)
+	stats.Total++
+	if !ok {
+		return fingerprintVerdict{New: true}
+	}
+
+	stats.LastSeen = now
+	stats.SinceAlert++
+	if now.Sub(stats.LastAlerted) < s.cooldown {
+		return fingerprintVerdict{Suppressed: true}
+	}
+
+	verdict := fingerprintVerdict{Repeats: stats.SinceAlert, Window: now.Sub(stats.LastAlerted)}
+	stats.LastAlerted = now
+	stats.SinceAlert = 0
+	return verdict
+}
+
+func (s *fingerprintStore) expireCooldowns(now time.Time) []fingerprintSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summaries []fingerprintSummary
+	for _, stats := range s.entries {
+		if stats.SinceAlert == 0 || now.Sub(stats.LastAlerted) < s.cooldown {
+			continue
+		}
+		summaries = append(summaries, fingerprintSummary{
+			ContainerID: stats.ContainerID,
+			Container:   stats.Container,
+			Rule:        stats.Rule,
+			Severity:    stats.Severity,
+			Sample:      stats.Sample,
+			Repeats:     stats.SinceAlert,
+			Window:      now.Sub(stats.LastAlerted),
+		})
+		stats.LastAlerted = now
+		stats.SinceAlert = 0
+		s.dirty = true
+	}
+	if s.pruneLocked(now) {
+		s.dirty = true
+	}
+	return summaries
+}
+
+func (s *fingerprintStore) pruneLocked(now time.Time) bool {
+	pruned := false
+	for key, stats := range s.entries {
+		if now.Sub(stats.LastSeen) > fingerprintsRetention {
+			delete(s.entries, key)
+			pruned = true
+		}
+	}
+	if len(s.entries) <= maxFingerprints {
+		return pruned
+	}
+
+	keys := make([]string, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return s.entries[keys[i]].LastSeen.Before(s.entries[keys[j]].LastSeen) })
+	for _, key := range keys[:len(keys)-maxFingerprints] {
+		delete(s.entries, key)
+	}
+	return true
+}
+
+func (s *fingerprintStore) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return
+	}
+	if err := storage.SaveJSON(s.path, s.entries); err != nil {
+		log.Printf("Error saving error fingerprints: %v", err)
+		return
+	}
+	s.dirty = false
+}
+
+func (s *fingerprintStore) run(ctx context.Context, summarize func(fingerprintSummary)) {
+	ticker := time.NewTicker(cursorsFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			for _, summary := range s.expireCooldowns(now) {
+				summarize(summary)
+			}
+			s.flush()
+		case <-ctx.Done():
+			s.flush()
+			return
+		}
+	}
+}
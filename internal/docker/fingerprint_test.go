@@ -0,0 +1,79 @@
+package docker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeErrorText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"2024-05-01T12:30:45.123Z ERROR request failed", "<ts> ERROR request failed"},
+		{"2024-05-01 12:30:45+02:00 ERROR request failed", "<ts> ERROR request failed"},
+		{"12:30:45 ERROR request failed", "<time> ERROR request failed"},
+		{"user 3f2504e0-4f89-11d3-9a0c-0305e82c3301 not found", "user <uuid> not found"},
+		{"dial tcp 10.0.3.17:5432: connection refused", "dial tcp <ip>: connection refused"},
+		{"listen on fe80::1ff:fe23:4567:890a failed", "listen on <ip> failed"},
+		{"dial 2001:db8:85a3:0:0:8a2e:370:7334 failed", "dial <ip> failed"},
+		{"nil pointer at 0x7ffd1a2b", "nil pointer at <hex>"},
+		{"container 4f3c2a1b9d8e7f60 exited", "container <hex> exited"},
+		{"retry 3 of 5 after 250ms", "retry <n> of <n> after <n>ms"},
+		{"  too   many \t spaces  ", "too many spaces"},
+	}
+	for _, tt := range tests {
+		if got := normalizeErrorText(tt.text); got != tt.want {
+			t.Errorf("normalizeErrorText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestErrorFingerprint(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b [2]string
+		same bool
+	}{
+		{"variable parts", [2]string{"error", "2024-05-01T12:00:00Z timeout after 30s id=42"}, [2]string{"error", "2024-05-02T08:15:10Z timeout after 31s id=7"}, true},
+		{"different text", [2]string{"error", "timeout"}, [2]string{"error", "connection refused"}, false},
+		{"different rule", [2]string{"error", "disk full"}, [2]string{"fatal", "disk full"}, false},
+	}
+	for _, tt := range tests {
+		same := errorFingerprint(tt.a[0], tt.a[1]) == errorFingerprint(tt.b[0], tt.b[1])
+		if same != tt.same {
+			t.Errorf("%s: same fingerprint = %v, want %v", tt.name, same, tt.same)
+		}
+	}
+}
+
+func TestFingerprintStoreObserve(t *testing.T) {
+	store := loadFingerprintStore(t.TempDir(), 10*time.Minute)
+	info := containerInfo{ID: "abc", Name: "api"}
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		after time.Duration
+		want  fingerprintVerdict
+	}{
+		{0, fingerprintVerdict{New: true}},
+		{time.Minute, fingerprintVerdict{Suppressed: true}},
+		{2 * time.Minute, fingerprintVerdict{Suppressed: true}},
+		{11 * time.Minute, fingerprintVerdict{Repeats: 3, Window: 11 * time.Minute}},
+		{12 * time.Minute, fingerprintVerdict{Suppressed: true}},
+	}
+	for _, step := range steps {
+		got := store.observe(info, "fp", "error", "error", "sample", start.Add(step.after))
+		if got != step.want {
+			t.Errorf("after %s: got %+v, want %+v", step.after, got, step.want)
+		}
+	}
+
+	summaries := store.expireCooldowns(start.Add(25 * time.Minute))
+	if len(summaries) != 1 || summaries[0].Repeats != 1 || summaries[0].Container != "api" {
+		t.Errorf("expireCooldowns = %+v, want one summary with 1 repeat", summaries)
+	}
+	if summaries := store.expireCooldowns(start.Add(40 * time.Minute)); len(summaries) != 0 {
+		t.Errorf("expireCooldowns repeated the summary: %+v", summaries)
+	}
+}
@@ -0,0 +1,215 @@
+package docker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+)
+
+const (
+	followFlushInterval = 2 * time.Second
+	followMaxBatch      = 500
+)
+
+var (
+	lifecycleSubscribers []chan events.Message
+	lifecycleMux         = &sync.Mutex{}
+)
+
+func subscribeContainerLifecycle() <-chan events.Message {
+	ch := make(chan events.Message, 64)
+	lifecycleMux.Lock()
+	lifecycleSubscribers = append(lifecycleSubscribers, ch)
+	lifecycleMux.Unlock()
+	return ch
+}
+
+func publishContainerLifecycle(event events.Message) {
+	lifecycleMux.Lock()
+	defer lifecycleMux.Unlock()
+
+	for _, ch := range lifecycleSubscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Dropping lifecycle event %s for container %s: subscriber is busy", event.Status, event.ID[:12])
+		}
+	}
+}
+
+type followStream struct {
+	cancel context.CancelFunc
+	died   bool
+}
+
+type logFollower struct {
+	monitor *logMonitor
+	mu      sync.Mutex
+	streams map[string]*followStream
+}
+
+func newLogFollower(monitor *logMonitor) *logFollower {
+	return &logFollower{
+		monitor: monitor,
+		streams: make(map[string]*followStream),
+	}
+}
+
+func (f *logFollower) run(ctx context.Context) {
+	lifecycle := subscribeContainerLifecycle()
+
+	ticker := time.NewTicker(f.monitor.cfg.PollInterval)
+	defer ticker.Stop()
+
+	f.reconcile(ctx)
+	for {
+		select {
+		case event := <-lifecycle:
+			switch event.Status {
+			case "start":
+				f.attach(ctx, infoFromEvent(event), time.Unix(0, event.TimeNano))
+			case "die":
+				id, stream := event.ID, f.markDied(event.ID)
+				if stream != nil {
+					time.AfterFunc(followFlushInterval, func() { f.detach(id, stream) })
+				}
+			case "destroy":
+				f.detach(event.ID, nil)
+				f.monitor.cursors.forget(event.ID)
+				f.monitor.recent.forget(event.ID)
+			}
+		case <-ticker.C:
+			f.reconcile(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (f *logFollower) reconcile(ctx context.Context) {
+	containers, err := DockerClient.ContainerList(ctx, types.ContainerListOptions{})
+	if err != nil {
+		log.Printf("Error fetching container list: %v", err)
+		return
+	}
+	for _, container := range containers {
+		f.attach(ctx, infoFromContainer(container), time.Now())
+	}
+}
+
+func (f *logFollower) attach(ctx context.Context, info containerInfo, since time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if existing, ok := f.streams[info.ID]; ok && !existing.died {
+		return
+	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	stream := &followStream{cancel: cancel}
+	f.streams[info.ID] = stream
+	go f.follow(streamCtx, stream, info, since)
+}
+
+func (f *logFollower) markDied(id string) *followStream {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stream := f.streams[id]
+	if stream != nil {
+		stream.died = true
+	}
+	return stream
+}
+
+func (f *logFollower) detach(id string, only *followStream) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if only != nil {
+		only.cancel()
+	}
+	stream, ok := f.streams[id]
+	if !ok || (only != nil && stream != only) {
+		return
+	}
+	stream.cancel()
+	delete(f.streams, id)
+}
+
+func (f *logFollower) follow(ctx context.Context, stream *followStream, info containerInfo, since time.Time) {
+	id, name := info.ID, info.Name
+	defer f.detach(id, stream)
+
+	tty, err := containerUsesTTY(ctx, id)
+	if err != nil {
+		log.Printf("Error inspecting container %s: %v", name, err)
+		return
+	}
+
+	cursor, hasCursor := f.monitor.cursors.get(id)
+	if hasCursor {
+		since = cursor.Add(time.Nanosecond)
+	}
+
+	out, err := DockerClient.ContainerLogs(ctx, id, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: true,
+		Since:      formatEventTimestamp(since.UnixNano()),
+	})
+	if err != nil {
+		log.Printf("Error following logs for container %s: %v", name, err)
+		return
+	}
+	defer out.Close()
+	log.Printf("Following logs of container %s", name)
+
+	lines := make(chan LogLine, 256)
+	go func() {
+		defer close(lines)
+		if err := ReadLogLines(out, tty, true, func(line LogLine) {
+			if hasCursor && !line.Timestamp.After(cursor) {
+				return
+			}
+			lines <- line
+		}); err != nil && ctx.Err() == nil {
+			log.Printf("Error reading log stream of container %s: %v", name, err)
+		}
+	}()
+
+	ticker := time.NewTicker(followFlushInterval)
+	defer ticker.Stop()
+
+	aggregator := newMultilineAggregator()
+	var batch []logEntry
+	flush := func() {
+		if len(batch) > 0 {
+			f.monitor.processEntries(info, batch)
+			batch = nil
+		}
+	}
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				batch = append(batch, aggregator.Flush(time.Time{})...)
+				flush()
+				log.Printf("Stopped following logs of container %s", name)
+				return
+			}
+			batch = append(batch, aggregator.Add(line, time.Now())...)
+			if len(batch) >= followMaxBatch {
+				flush()
+			}
+		case now := <-ticker.C:
+			batch = append(batch, aggregator.Flush(now.Add(-followFlushInterval/2))...)
+			flush()
+		}
+	}
+}
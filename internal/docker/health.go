@@ -0,0 +1,137 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/incident"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const healthStatusPrefix = "health_status: "
+
+func HealthLabel(status string) string {
+	switch status {
+	case types.Healthy:
+		return "💚 Healthy"
+	case types.Unhealthy:
+		return "💔 Unhealthy"
+	case types.Starting:
+		return "⏳ Starting"
+	}
+	return ""
+}
+
+func HealthFromListStatus(status string) string {
+	switch {
+	case strings.Contains(status, "(unhealthy)"):
+		return types.Unhealthy
+	case strings.Contains(status, "(healthy)"):
+		return types.Healthy
+	case strings.Contains(status, "(health: starting)"):
+		return types.Starting
+	}
+	return ""
+}
+
+func LastHealthProbe(health *types.Health) *types.HealthcheckResult {
+	if health == nil || len(health.Log) == 0 {
+		return nil
+	}
+	return health.Log[len(health.Log)-1]
+}
+
+func FormatHealthProbe(probe *types.HealthcheckResult) string {
+	output := strings.TrimSpace(probe.Output)
+	if output == "" {
+		output = "(no output)"
+	}
+	filtered := utils.RemoveControlCharactersRegex(strings.ToValidUTF8(output, ""))
+	return fmt.Sprintf(
+		"<pre>"+
+			"┌ Exit code: %d\n"+
+			"├ At: %s\n"+
+			"└ Output: %s"+
+			"</pre>",
+		probe.ExitCode,
+		probe.End.Format("2006-01-02 15:04:05"),
+		utils.EscapeHTML(utils.Truncate(filtered, 1000)),
+	)
+}
+
+func (m *eventMonitor) handleHealthEvent(ctx context.Context, event events.Message) {
+	status := strings.TrimPrefix(event.Status, healthStatusPrefix)
+	name := event.Actor.Attributes["name"]
+
+	switch status {
+	case types.Unhealthy:
+		if autohealEnabled(event) {
+			m.healer.trigger(ctx, event.ID, name, healReasonUnhealthy)
+		}
+		if _, alreadyUnhealthy := m.unhealthySince[event.ID]; alreadyUnhealthy {
+			return
+		}
+		since := time.Unix(0, event.TimeNano)
+		m.unhealthySince[event.ID] = since
+		log.Printf("Container unhealthy: ID=%s, Name=%s", event.ID[:12], name)
+		recordIncident(event.ID, name, incident.KindUnhealthy, "Health check failing", since)
+		m.problems.expect(event.ID, problemUnhealthy, since)
+		go m.sendUnhealthyAlert(ctx, event.ID, name, since)
+	case types.Healthy:
+		since, wasUnhealthy := m.unhealthySince[event.ID]
+		if !wasUnhealthy {
+			return
+		}
+		delete(m.unhealthySince, event.ID)
+		log.Printf("Container healthy again: ID=%s, Name=%s", event.ID[:12], name)
+		recordIncident(event.ID, name, incident.KindHealthy, "Healthy again", time.Unix(0, event.TimeNano))
+		if m.resolveProblem(event.ID, problemUnhealthy, time.Unix(0, event.TimeNano)) {
+			return
+		}
+
+		message := fmt.Sprintf(
+			"💚 <b>Container <u>%s</u> is healthy again</b>\n\n"+
+				"<pre>"+
+				"┌ ID: %s\n"+
+				"└ Unhealthy for: %s"+
+				"</pre>",
+			name,
+			event.ID[:12],
+			time.Unix(0, event.TimeNano).Sub(since).Round(time.Second),
+		)
+		m.sendContainerAlert(event.ID, name, "healthy", message, false)
+	}
+}
+
+func (m *eventMonitor) sendUnhealthyAlert(ctx context.Context, id, name string, since time.Time) {
+	inspectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	message := fmt.Sprintf(
+		"💔 <b>Container <u>%s</u> is unhealthy</b>\n\n"+
+			"<pre>"+
+			"┌ ID: %s\n"+
+			"└ Name: %s"+
+			"</pre>",
+		name,
+		id[:12],
+		name,
+	)
+	container, err := DockerClient.ContainerInspect(inspectCtx, id)
+	if err != nil {
+		log.Printf("Error inspecting unhealthy container %s: %v", name, err)
+	} else if probe := LastHealthProbe(container.State.Health); probe != nil {
+		message += fmt.Sprintf(
+			"\n\n🩺 <b>Last failing probe</b> (failing streak: %d):\n%s",
+			container.State.Health.FailingStreak,
+			FormatHealthProbe(probe),
+		)
+	}
+	m.openExpectedProblem(id, name, problemUnhealthy, m.sendContainerAlert(id, name, "unhealthy", message, false), since)
+}
@@ -0,0 +1,246 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+
+	maxLogLineBytes = 64 * 1024
+	logHeaderSize   = 8
+)
+
+type LogLine struct {
+	Stream    string
+	Text      string
+	Timestamp time.Time
+	Truncated bool
+}
+
+type lineSplitter struct {
+	stream     string
+	timestamps bool
+	buf        []byte
+	truncated  bool
+	discard    bool
+	emit       func(LogLine)
+}
+
+func (s *lineSplitter) Write(p []byte) {
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		chunk := p
+		if i >= 0 {
+			chunk = p[:i]
+		}
+
+		if !s.discard {
+			room := maxLogLineBytes - len(s.buf)
+			if len(chunk) > room {
+				s.buf = append(s.buf, chunk[:room]...)
+				s.truncated = true
+				s.discard = true
+			} else {
+				s.buf = append(s.buf, chunk...)
+			}
+		}
+
+		if i < 0 {
+			return
+		}
+		s.flush()
+		p = p[i+1:]
+	}
+}
+
+func (s *lineSplitter) flush() {
+	line := LogLine{
+		Stream:    s.stream,
+		Text:      string(bytes.TrimSuffix(s.buf, []byte("\r"))),
+		Truncated: s.truncated,
+	}
+	if s.timestamps {
+		line.Timestamp, line.Text = splitLogTimestamp(line.Text)
+	}
+	s.emit(line)
+	s.buf = s.buf[:0]
+	s.truncated = false
+	s.discard = false
+}
+
+func (s *lineSplitter) Close() {
+	if len(s.buf) > 0 || s.truncated {
+		s.flush()
+	}
+}
+
+func splitLogTimestamp(text string) (time.Time, string) {
+	i := strings.IndexByte(text, ' ')
+	if i < 0 {
+		i = len(text)
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, text[:i])
+	if err != nil {
+		return time.Time{}, text
+	}
+	if i < len(text) {
+		i++
+	}
+	return timestamp, text[i:]
+}
+
+func ReadLogLines(r io.Reader, tty, timestamps bool, handle func(LogLine)) error {
+	stdout := &lineSplitter{stream: StreamStdout, timestamps: timestamps, emit: handle}
+	stderr := &lineSplitter{stream: StreamStderr, timestamps: timestamps, emit: handle}
+	defer stdout.Close()
+	defer stderr.Close()
+
+	reader := bufio.NewReaderSize(r, 32*1024)
+	if tty {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := reader.Read(buf)
+			stdout.Write(buf[:n])
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	header := make([]byte, logHeaderSize)
+	payload := make([]byte, 32*1024)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("truncated log frame header")
+			}
+			return err
+		}
+
+		var splitter *lineSplitter
+		switch stdcopy.StdType(header[0]) {
+		case stdcopy.Stdin, stdcopy.Stdout:
+			splitter = stdout
+		case stdcopy.Stderr:
+			splitter = stderr
+		case stdcopy.Systemerr:
+			splitter = nil
+		default:
+			return fmt.Errorf("unrecognized log stream type %d", header[0])
+		}
+
+		size := int(binary.BigEndian.Uint32(header[4:logHeaderSize]))
+		var systemErr bytes.Buffer
+		for size > 0 {
+			chunk := payload
+			if size < len(chunk) {
+				chunk = chunk[:size]
+			}
+			n, err := io.ReadFull(reader, chunk)
+			if splitter != nil {
+				splitter.Write(chunk[:n])
+			} else {
+				systemErr.Write(chunk[:n])
+			}
+			if err != nil {
+				return err
+			}
+			size -= n
+		}
+		if splitter == nil {
+			return fmt.Errorf("error from daemon in log stream: %s", systemErr.String())
+		}
+	}
+}
+
+func ReadContainerLogs(ctx context.Context, containerID string, options types.ContainerLogsOptions, handle func(LogLine)) error {
+	tty, err := containerUsesTTY(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	out, err := DockerClient.ContainerLogs(ctx, containerID, options)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	err = ReadLogLines(out, tty, options.Timestamps, handle)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func LogLinesAfter(ctx context.Context, containerID string, after time.Time, limit int) ([]LogLine, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var lines []LogLine
+	options := types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Since:      formatEventTimestamp(after.UnixNano() + 1),
+	}
+	err := ReadContainerLogs(ctx, containerID, options, func(line LogLine) {
+		if len(lines) >= limit || !line.Timestamp.After(after) {
+			return
+		}
+		lines = append(lines, line)
+		if len(lines) >= limit {
+			cancel()
+		}
+	})
+	return lines, err
+}
+
+var (
+	ttyCache    = make(map[string]bool)
+	ttyCacheMux = &sync.Mutex{}
+)
+
+func containerUsesTTY(ctx context.Context, containerID string) (bool, error) {
+	ttyCacheMux.Lock()
+	tty, ok := ttyCache[containerID]
+	ttyCacheMux.Unlock()
+	if ok {
+		return tty, nil
+	}
+
+	container, err := DockerClient.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return false, err
+	}
+	tty = container.Config != nil && container.Config.Tty
+
+	ttyCacheMux.Lock()
+	ttyCache[containerID] = tty
+	ttyCacheMux.Unlock()
+	return tty, nil
+}
+
+func forgetContainerTTY(containerID string) {
+	ttyCacheMux.Lock()
+	delete(ttyCache, containerID)
+	ttyCacheMux.Unlock()
+}
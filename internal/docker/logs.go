@@ -0,0 +1,391 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/alert"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/config"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/incident"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/rules"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/silence"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
+)
+
+const (
+	telegramMessageLimit = 4096
+	telegramCaptionLimit = 1024
+)
+
+type containerInfo struct {
+	ID      string
+	Name    string
+	Image   string
+	Service string
+}
+
+func infoFromContainer(c types.Container) containerInfo {
+	return containerInfo{
+		ID:      c.ID,
+		Name:    strings.TrimPrefix(c.Names[0], "/"),
+		Image:   c.Image,
+		Service: c.Labels[rules.ComposeServiceLabel],
+	}
+}
+
+func infoFromEvent(event events.Message) containerInfo {
+	return containerInfo{
+		ID:      event.ID,
+		Name:    event.Actor.Attributes["name"],
+		Image:   event.Actor.Attributes["image"],
+		Service: event.Actor.Attributes[rules.ComposeServiceLabel],
+	}
+}
+
+func (c containerInfo) target() rules.Target {
+	return rules.Target{Name: c.Name, Image: c.Image, Service: c.Service}
+}
+
+type matchedEntry struct {
+	logEntry
+	Parsed    rules.Line
+	Match     rules.Match
+	Verdict   fingerprintVerdict
+	LineIndex int
+}
+
+type logMonitor struct {
+	cfg      *config.Config
+	notifier notification.Notifier
+	rules    *rules.Engine
+	json     rules.JSONOptions
+	cursors  *cursorStore
+	recent   *recentLines
+
+	fingerprints *fingerprintStore
+}
+
+func MonitorContainerLogs(ctx context.Context, cfg *config.Config, ruleEngine *rules.Engine, notifier notification.Notifier) {
+	monitor := &logMonitor{
+		cfg:      cfg,
+		notifier: notifier,
+		rules:    ruleEngine,
+		json: rules.JSONOptions{
+			LevelFields:   cfg.JSONLevelFields,
+			MessageFields: cfg.JSONMessageFields,
+			DisplayFields: cfg.JSONDisplayFields,
+		},
+		cursors: loadCursorStore(cfg.StateDir),
+		recent:  newRecentLines(cfg.AlertContextLines),
+
+		fingerprints: loadFingerprintStore(cfg.StateDir, cfg.ErrorCooldown),
+	}
+	go monitor.cursors.run(ctx)
+	go monitor.fingerprints.run(ctx, monitor.sendRepeatSummary)
+
+	if cfg.LogMode == config.LogModePoll {
+		monitor.poll(ctx)
+		return
+	}
+	newLogFollower(monitor).run(ctx)
+}
+
+func (m *logMonitor) matchEntry(info containerInfo, entry logEntry) (matchedEntry, bool) {
+	head := entry.Head()
+	matched := matchedEntry{logEntry: entry, Parsed: m.json.Parse(head.Stream, head.Text)}
+
+	match, found := m.rules.Match(info.target(), matched.Parsed)
+	for _, line := range entry.Lines[1:] {
+		lineMatch, ok := m.rules.Match(info.target(), rules.Line{Stream: line.Stream, Text: line.Text})
+		if ok && (!found || lineMatch.Severity.Rank() > match.Severity.Rank()) {
+			match, found = lineMatch, true
+		}
+	}
+	matched.Match = match
+	return matched, found
+}
+
+func (m *logMonitor) reportErrors(info containerInfo, entries []logEntry) {
+	now := time.Now()
+	previous := m.recent.get(info.ID)
+	window := previous
+	var windowEnd time.Time
+	var newMatches, repeatedMatches []matchedEntry
+	for _, entry := range entries {
+		lineIndex := len(window)
+		for _, line := range entry.Lines {
+			window = append(window, fmt.Sprintf("[%s] %s", line.Stream, line.Text))
+		}
+		if entry.Last().Timestamp.After(windowEnd) {
+			windowEnd = entry.Last().Timestamp
+		}
+
+		matched, ok := m.matchEntry(info, entry)
+		if !ok || silence.IsSilenced(info.Name, entry.Text()) {
+			continue
+		}
+		matched.LineIndex = lineIndex
+
+		matched.Verdict = m.fingerprints.observe(
+			info,
+			errorFingerprint(matched.Match.Rule, matched.Parsed.Text),
+			matched.Match.Rule,
+			string(matched.Match.Severity),
+			matched.Parsed.Text,
+			now,
+		)
+		switch {
+		case matched.Verdict.Suppressed:
+			continue
+		case matched.Verdict.New:
+			newMatches = append(newMatches, matched)
+		default:
+			repeatedMatches = append(repeatedMatches, matched)
+		}
+	}
+
+	m.recent.push(info.ID, window[len(previous):])
+
+	if len(newMatches) > 0 {
+		m.sendErrorAlert(info, true, newMatches, window, windowEnd)
+	}
+	if len(repeatedMatches) > 0 {
+		m.sendErrorAlert(info, false, repeatedMatches, window, windowEnd)
+	}
+}
+
+func (m *logMonitor) sendErrorAlert(info containerInfo, newSignature bool, matches []matchedEntry, window []string, windowEnd time.Time) {
+	severity := rules.SeverityInfo
+	for _, matched := range matches {
+		if matched.Match.Severity.Rank() > severity.Rank() {
+			severity = matched.Match.Severity
+		}
+	}
+
+	header := fmt.Sprintf("%s <b>Container <u>%s</u> encountered errors:</b>\n\n", severity.Icon(), info.Name)
+	if newSignature {
+		header = fmt.Sprintf("%s 🆕 <b>New error signature in container <u>%s</u>:</b>\n\n", severity.Icon(), info.Name)
+	}
+	var errorMessages []string
+	var summaries []string
+	var logLines []string
+	for _, matched := range matches[:utils.Min(3, len(matches))] {
+		label := fmt.Sprintf(
+			"%s <i>%s · %s · %s</i>",
+			matched.Match.Severity.Icon(),
+			utils.EscapeHTML(matched.Match.Rule),
+			matched.Match.Severity,
+			matched.Head().Stream,
+		)
+		if matched.Verdict.Repeats > 1 {
+			label += fmt.Sprintf("\n🔁 Seen %d times in the last %s", matched.Verdict.Repeats, formatWindow(matched.Verdict.Window))
+		}
+		errorMessages = append(errorMessages, fmt.Sprintf("%s\n<pre>%s</pre>", label, m.formatEntry(matched)))
+		summaries = append(summaries, fmt.Sprintf("%s\n<pre>%s</pre>", label, m.formatLine(matched.Head(), matched.Parsed, 200)))
+	}
+	errorAlert := &alert.ErrorAlert{
+		ContainerID:   info.ID,
+		ContainerName: info.Name,
+		CreatedAt:     time.Now(),
+		Window:        window,
+		WindowEnd:     windowEnd,
+	}
+	for _, matched := range matches {
+		logLine := fmt.Sprintf("[%s] [%s/%s] %s", matched.Head().Stream, matched.Match.Rule, matched.Match.Severity, matched.Text())
+		logLines = append(logLines, logLine)
+		errorAlert.Entries = append(errorAlert.Entries, alert.Entry{
+			Text:      logLine,
+			LineIndex: matched.LineIndex,
+			LineCount: len(matched.Lines),
+		})
+	}
+	log.Printf("Errors detected in container %s:\n%s", info.Name, strings.Join(logLines, "\n"))
+	recordIncident(info.ID, info.Name, incident.KindErrors, fmt.Sprintf(
+		"%d error entries (%s · %s): %s",
+		len(matches),
+		matches[0].Match.Rule,
+		severity,
+		utils.Truncate(matches[0].Parsed.Text, 200),
+	), errorAlert.CreatedAt)
+	keyboard := alert.ErrorKeyboard(alert.Add(errorAlert), info.ID)
+
+	if len(matches) > 3 {
+		errorMessages = append(errorMessages, fmt.Sprintf("<i>…and %d more</i>", len(matches)-3))
+	}
+	critical := severity == rules.SeverityCritical
+	message := header + strings.Join(errorMessages, "\n")
+	if utf8.RuneCountInString(message) <= telegramMessageLimit {
+		alert.Send(m.notifier, m.cfg.TelegramChatID, info.ID, message, keyboard, critical)
+		return
+	}
+
+	caption := header + strings.Join(summaries, "\n") + "\n\n📎 Full trace attached."
+	if utf8.RuneCountInString(caption) > telegramCaptionLimit {
+		caption = header + fmt.Sprintf("%d error entries, full traces attached.", len(matches))
+	}
+	fileName := fmt.Sprintf("%s-errors-%s.log", info.Name, time.Now().Format("20060102-150405"))
+	alert.SendDocument(m.notifier, m.cfg.TelegramChatID, info.ID, fileName, []byte(strings.Join(logLines, "\n\n")), caption, keyboard, critical)
+}
+
+func (m *logMonitor) sendRepeatSummary(summary fingerprintSummary) {
+	if summary.ContainerID == "" || silence.IsSilenced(summary.Container, summary.Sample) {
+		return
+	}
+	severity := rules.Severity(summary.Severity)
+	message := fmt.Sprintf(
+		"🔁 <b>Repeated errors in container <u>%s</u></b>\n\n"+
+			"%s <i>%s · %s</i>\n"+
+			"<pre>%s</pre>\n"+
+			"Seen %d more times in the last %s",
+		utils.EscapeHTML(summary.Container),
+		severity.Icon(),
+		utils.EscapeHTML(summary.Rule),
+		severity,
+		cleanLogText(summary.Sample, 300),
+		summary.Repeats,
+		formatWindow(summary.Window),
+	)
+	log.Printf("Repeated errors in container %s: %d times (%s)", summary.Container, summary.Repeats, summary.Rule)
+	alert.Send(m.notifier, m.cfg.TelegramChatID, summary.ContainerID, message, alert.EventKeyboard(summary.ContainerID), false)
+}
+
+func formatWindow(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%d min", int(d.Minutes()))
+	}
+	return d.Round(time.Minute).String()
+}
+
+func (m *logMonitor) formatEntry(matched matchedEntry) string {
+	lines := []string{m.formatLine(matched.Head(), matched.Parsed, 0)}
+	for _, line := range matched.Lines[1:] {
+		lines = append(lines, cleanLogText(line.Text, 0))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func cleanLogText(text string, maxRunes int) string {
+	filtered := utils.RemoveControlCharactersRegex(strings.ToValidUTF8(text, ""))
+	if maxRunes > 0 {
+		filtered = utils.Truncate(filtered, maxRunes)
+	}
+	return utils.EscapeHTML(filtered)
+}
+
+func (m *logMonitor) formatLine(line LogLine, parsed rules.Line, maxRunes int) string {
+	clean := func(text string) string {
+		return cleanLogText(text, maxRunes)
+	}
+	if parsed.Fields == nil {
+		return clean(line.Text)
+	}
+
+	text := clean(parsed.Text)
+	if parsed.LevelName != "" {
+		text = fmt.Sprintf("[%s] %s", clean(strings.ToUpper(parsed.LevelName)), text)
+	}
+	if keyFields := m.json.KeyFields(parsed); len(keyFields) > 0 {
+		text += "\n" + clean(strings.Join(keyFields, " "))
+	}
+	return text
+}
+
+func (m *logMonitor) processEntries(info containerInfo, entries []logEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	m.reportErrors(info, entries)
+
+	var latest time.Time
+	for _, entry := range entries {
+		if entry.Last().Timestamp.After(latest) {
+			latest = entry.Last().Timestamp
+		}
+	}
+	m.cursors.advance(info.ID, latest)
+}
+
+func (m *logMonitor) poll(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			containers, err := DockerClient.ContainerList(ctx, types.ContainerListOptions{All: true})
+			if err != nil {
+				log.Printf("Error fetching container list: %v", err)
+				continue
+			}
+
+			known := make(map[string]bool)
+			for _, container := range containers {
+				known[container.ID] = true
+			}
+			m.cursors.retain(known)
+			m.recent.retain(known)
+
+			for _, container := range containers {
+				if container.State != "running" {
+					continue
+				}
+
+				go func(c types.Container) {
+					info := infoFromContainer(c)
+					name := info.Name
+
+					tty, err := containerUsesTTY(ctx, c.ID)
+					if err != nil {
+						log.Printf("Error inspecting container %s: %v", name, err)
+						return
+					}
+
+					options := types.ContainerLogsOptions{
+						ShowStdout: true,
+						ShowStderr: true,
+						Timestamps: true,
+						Tail:       fmt.Sprintf("%d", m.cfg.TailCount),
+					}
+					cursor, hasCursor := m.cursors.get(c.ID)
+					if hasCursor {
+						options.Since = formatEventTimestamp(cursor.UnixNano() + 1)
+					}
+
+					out, err := DockerClient.ContainerLogs(ctx, c.ID, options)
+					if err != nil {
+						log.Printf("Error fetching logs for container %s: %v", name, err)
+						return
+					}
+					defer out.Close()
+
+					var lines []LogLine
+					err = ReadLogLines(out, tty, true, func(line LogLine) {
+						if hasCursor && !line.Timestamp.After(cursor) {
+							return
+						}
+						lines = append(lines, line)
+					})
+					if err != nil {
+						log.Printf("Error reading logs for container %s: %v", name, err)
+						return
+					}
+
+					m.processEntries(info, groupLogLines(lines))
+				}(container)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -1,164 +1,404 @@
 package docker
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"log"
-	"regexp"
 	"strings"
 	"time"
 
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/alert"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/config"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/incident"
 	"github.com/HarkushaVlad/docker-monitor-bot/internal/notification"
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/silence"
 	"github.com/HarkushaVlad/docker-monitor-bot/internal/utils"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
 )
 
-func MonitorDockerEvents(ctx context.Context, telegramChatID int64, notifier notification.Notifier) {
-	options := types.EventsOptions{}
-	eventCh, errCh := DockerClient.Events(ctx, options)
+const (
+	eventsInitialBackoff = time.Second
+	eventsMaxBackoff     = time.Minute
+)
+
+type eventMonitor struct {
+	chatID     int64
+	notifier   notification.Notifier
+	crashLoops *crashLoopTracker
+
+	unhealthySince map[string]time.Time
+	stopRequests   *stopRequests
+	oomKilled      map[string]bool
+	healer         *autoHealer
+	problems       *problemTracker
+	replayBefore   int64
+}
+
+func MonitorDockerEvents(ctx context.Context, cfg *config.Config, notifier notification.Notifier) {
+	monitor := &eventMonitor{
+		chatID:     cfg.TelegramChatID,
+		notifier:   notifier,
+		crashLoops: newCrashLoopTracker(cfg.CrashLoopThreshold, cfg.CrashLoopWindow),
+
+		unhealthySince: make(map[string]time.Time),
+		stopRequests:   newStopRequests(),
+		oomKilled:      make(map[string]bool),
+		healer:         newAutoHealer(cfg.TelegramChatID, notifier, cfg.AutohealMaxAttempts, cfg.AutohealBackoff, cfg.StopTimeout),
+		problems:       newProblemTracker(),
+	}
+	go monitor.watchCrashLoops(ctx)
+
+	var lastEventNano int64
+	backoff := eventsInitialBackoff
+	degraded := false
 
 	for {
-		select {
-		case event := <-eventCh:
-			if event.Type == events.ContainerEventType {
-				if event.Status == "start" {
-					message := fmt.Sprintf(
-						"🚀 <b>Container started</b>\n\n"+
-							"<pre>"+
-							"┌ ID: %s\n"+
-							"└ Name: %s"+
-							"</pre>",
-						event.ID[:12],
-						event.Actor.Attributes["name"],
-					)
-					log.Printf("Container started: ID=%s, Name=%s", event.ID[:12], event.Actor.Attributes["name"])
-					notifier.SendText(telegramChatID, message)
+		if degraded {
+			if _, err := DockerClient.Ping(ctx); err != nil {
+				log.Printf("Docker daemon is still unreachable: %v", err)
+				if !sleepContext(ctx, backoff) {
+					return
 				}
-				if event.Status == "die" || event.Status == "oom" {
-					message := fmt.Sprintf(
-						"❗️ <b>Container stopped</b>\n\n"+
-							"<pre>"+
-							"┌ ID: %s\n"+
-							"├ Name: %s\n"+
-							"└ Status: %s"+
-							"</pre>",
-						event.ID[:12],
-						event.Actor.Attributes["name"],
-						event.Status,
-					)
-					log.Printf("Container stopped: ID=%s, Name=%s, Status=%s", event.ID[:12], event.Actor.Attributes["name"], event.Status)
-					notifier.SendText(telegramChatID, message)
-				}
-			}
-		case err := <-errCh:
-			if err != nil {
-				log.Printf("Error receiving Docker events: %v", err)
-				time.Sleep(10 * time.Second)
+				backoff = nextBackoff(backoff)
+				continue
 			}
+			log.Println("Docker event stream restored")
+			notifier.SendText(cfg.TelegramChatID, "✅ <b>Monitoring restored</b>\n\nReconnected to the Docker event stream.")
+			degraded = false
+		}
+
+		connectedAt := time.Now()
+		if lastEventNano > 0 {
+			monitor.replayBefore = connectedAt.UnixNano()
+		}
+		err := consumeDockerEvents(ctx, lastEventNano, func(event events.Message) {
+			lastEventNano = event.TimeNano
+			monitor.handleEvent(ctx, event)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(connectedAt) > eventsMaxBackoff {
+			backoff = eventsInitialBackoff
 		}
+
+		log.Printf("Error receiving Docker events: %v", err)
+		if !degraded {
+			message := fmt.Sprintf(
+				"⚠️ <b>Monitoring degraded</b>\n\n"+
+					"Lost connection to the Docker event stream, reconnecting...\n"+
+					"<pre>%s</pre>",
+				utils.EscapeHTML(err.Error()),
+			)
+			notifier.SendText(cfg.TelegramChatID, message)
+			degraded = true
+		}
+
+		if !sleepContext(ctx, backoff) {
+			return
+		}
+		backoff = nextBackoff(backoff)
 	}
 }
 
-func MonitorContainerLogs(ctx context.Context, pollInterval time.Duration, tailCount int, telegramChatID int64, notifier notification.Notifier) {
-	ticker := time.NewTicker(pollInterval)
-	defer ticker.Stop()
+func consumeDockerEvents(ctx context.Context, sinceNano int64, handle func(events.Message)) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	errorRegex := regexp.MustCompile(`(?i)error`)
-	lastMarkers := make(map[string]string)
+	options := types.EventsOptions{
+		Filters: filters.NewArgs(filters.Arg("type", string(events.ContainerEventType))),
+	}
+	if sinceNano > 0 {
+		options.Since = formatEventTimestamp(sinceNano + 1)
+	}
 
+	eventCh, errCh := DockerClient.Events(streamCtx, options)
 	for {
 		select {
-		case <-ticker.C:
-			containers, err := DockerClient.ContainerList(ctx, types.ContainerListOptions{All: true})
-			if err != nil {
-				log.Printf("Error fetching container list: %v", err)
+		case event := <-eventCh:
+			if event.TimeNano <= sinceNano {
 				continue
 			}
+			sinceNano = event.TimeNano
+			handle(event)
+		case err := <-errCh:
+			if err == nil {
+				err = fmt.Errorf("event stream closed")
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *eventMonitor) handleEvent(ctx context.Context, event events.Message) {
+	if event.Type != events.ContainerEventType {
+		return
+	}
+	publishContainerLifecycle(event)
+	if strings.HasPrefix(event.Status, healthStatusPrefix) {
+		m.handleHealthEvent(ctx, event)
+		return
+	}
+	name := event.Actor.Attributes["name"]
+	requested := m.stopRequests.observe(event)
+
+	switch event.Status {
+	case "start":
+		if m.crashLoops.isLooping(event.ID) {
+			return
+		}
+		startedAt := time.Unix(0, event.TimeNano)
+		recordIncident(event.ID, name, incident.KindStart, "Container started", startedAt)
+		stoppedResolved := m.resolveProblem(event.ID, problemStopped, startedAt)
+		loopResolved := m.resolveProblem(event.ID, problemCrashLoop, startedAt)
+		if stoppedResolved || loopResolved {
+			log.Printf("Container started: ID=%s, Name=%s", event.ID[:12], name)
+			return
+		}
+
+		message := fmt.Sprintf(
+			"🚀 <b>Container started</b>\n\n"+
+				"<pre>"+
+				"┌ ID: %s\n"+
+				"└ Name: %s"+
+				"</pre>",
+			event.ID[:12],
+			name,
+		)
+		log.Printf("Container started: ID=%s, Name=%s", event.ID[:12], name)
+		m.sendContainerAlert(event.ID, name, "start", message, false)
+	case "die":
+		exitCode := event.Actor.Attributes["exitCode"]
+		if !requested && exitCode != "0" && autohealEnabled(event) {
+			m.healer.trigger(ctx, event.ID, name, fmt.Sprintf(healReasonExit, exitCode))
+		}
+		oomKilled := m.oomKilled[event.ID]
+		delete(m.oomKilled, event.ID)
+		if !requested {
+			recordIncident(event.ID, name, incident.KindDie, fmt.Sprintf("Exited with code %s", exitCode), time.Unix(0, event.TimeNano))
+		}
+
+		loopState, crashes := m.recordDeath(event, requested)
+		switch loopState {
+		case crashLoopStarted:
+			m.sendCrashLoopAlert(event.ID, name, crashes)
+			return
+		case crashLoopOngoing:
+			log.Printf("Container %s died again during crash loop (exit code %s)", name, exitCode)
+			return
+		}
+		m.problems.expect(event.ID, problemStopped, time.Unix(0, event.TimeNano))
+		go m.sendStoppedAlert(ctx, event, oomKilled, event.TimeNano < m.replayBefore)
+	case "oom":
+		recordIncident(event.ID, name, incident.KindOOM, "Out of memory", time.Unix(0, event.TimeNano))
+		m.oomKilled[event.ID] = true
+	case "destroy":
+		forgetContainerTTY(event.ID)
+		delete(m.unhealthySince, event.ID)
+		delete(m.oomKilled, event.ID)
+		m.problems.forget(event.ID)
+		incident.Close(event.ID, time.Unix(0, event.TimeNano))
+	}
+}
+
+func (m *eventMonitor) recordDeath(event events.Message, requested bool) (crashLoopState, []crashRecord) {
+	if requested {
+		return crashLoopNone, nil
+	}
+	return m.crashLoops.recordDie(event.ID, event.Actor.Attributes["name"], event.Actor.Attributes["exitCode"], time.Unix(0, event.TimeNano))
+}
+
+func (m *eventMonitor) sendStoppedAlert(ctx context.Context, event events.Message, oomKilled, replayed bool) {
+	name := event.Actor.Attributes["name"]
+	stoppedAt := time.Unix(0, event.TimeNano)
+	log.Printf("Container stopped: ID=%s, Name=%s, Status=%s", event.ID[:12], name, event.Status)
+
+	var details *exitDetails
+	if !replayed {
+		inspectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		var err error
+		details, err = inspectExit(inspectCtx, event.ID)
+		if err != nil {
+			log.Printf("Error inspecting stopped container %s: %v", name, err)
+		}
+	}
+	status, subject := event.Status, event.Status
+	if oomKilled || (details != nil && details.OOMKilled) {
+		status, subject = "die (out of memory)", "oom"
+	}
+
+	if details == nil {
+		message := fmt.Sprintf(
+			"❗️ <b>Container stopped</b>\n\n"+
+				"<pre>"+
+				"┌ ID: %s\n"+
+				"├ Name: %s\n"+
+				"├ Status: %s\n"+
+				"└ Exit code: %s"+
+				"</pre>",
+			event.ID[:12],
+			name,
+			status,
+			event.Actor.Attributes["exitCode"],
+		)
+		if replayed {
+			message += fmt.Sprintf("\n\n⏪ <i>Received after reconnecting to Docker, the container stopped at %s. Details are omitted because its current state may differ.</i>", stoppedAt.Format("2006-01-02 15:04:05"))
+		}
+		m.openExpectedProblem(event.ID, name, problemStopped, m.sendContainerAlert(event.ID, name, subject, message, subject == "oom"), stoppedAt)
+		return
+	}
 
-			for _, container := range containers {
-				if container.State != "running" {
+	message := fmt.Sprintf(
+		"❗️ <b>Container stopped</b>\n\n"+
+			"<pre>"+
+			"┌ ID: %s\n"+
+			"├ Name: %s\n"+
+			"├ Status: %s\n"+
+			"%s"+
+			"</pre>",
+		event.ID[:12],
+		name,
+		status,
+		formatExitDetails(details),
+	)
+	if len(details.LastLines) > 0 {
+		message += fmt.Sprintf("\n\n📄 <b>Last log lines:</b>\n<pre>%s</pre>", formatLogLines(details.LastLines))
+	}
+	m.openExpectedProblem(event.ID, name, problemStopped, m.sendContainerAlert(event.ID, name, subject, message, subject == "oom"), stoppedAt)
+}
+
+func (m *eventMonitor) openExpectedProblem(id, name string, kind problemKind, tracked *alert.Tracked, since time.Time) {
+	if resolvedAt, resolved := m.problems.fulfil(id, kind, tracked); resolved && tracked != nil {
+		log.Printf("Container %s recovered before its %s alert was sent", name, kind)
+		alert.Resolve(m.notifier, tracked, resolvedAt.Sub(since))
+	}
+}
+
+func (m *eventMonitor) sendCrashLoopAlert(id, name string, crashes []crashRecord) {
+	var exitCodes []string
+	for _, crash := range crashes[len(crashes)-utils.Min(5, len(crashes)):] {
+		exitCodes = append(exitCodes, crash.ExitCode)
+	}
+	message := fmt.Sprintf(
+		"🔁 <b>Container <u>%s</u> is in a crash loop</b>\n\n"+
+			"<pre>"+
+			"┌ ID: %s\n"+
+			"├ Dies: %d in %s\n"+
+			"└ Last exit codes: %s"+
+			"</pre>\n\n"+
+			"Further start/stop notifications are suppressed until the loop ends.",
+		name,
+		id[:12],
+		len(crashes),
+		crashes[len(crashes)-1].At.Sub(crashes[0].At).Round(time.Second),
+		strings.Join(exitCodes, ", "),
+	)
+	log.Printf("Crash loop detected: ID=%s, Name=%s, Dies=%d", id[:12], name, len(crashes))
+	recordIncident(id, name, incident.KindCrashLoop, fmt.Sprintf("Crash loop: %d dies, last exit codes %s", len(crashes), strings.Join(exitCodes, ", ")), crashes[len(crashes)-1].At)
+	tracked := m.sendContainerAlert(id, name, "crashloop", message, true)
+	m.problems.open(id, problemCrashLoop, tracked, crashes[0].At)
+}
+
+func (m *eventMonitor) watchCrashLoops(ctx context.Context) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			for _, loop := range m.crashLoops.sweep(now) {
+				if m.resolveRecoveredLoop(ctx, loop, now) {
+					log.Printf("Crash loop ended, container running again: ID=%s, Name=%s", loop.ID[:12], loop.Name)
 					continue
 				}
-
-				go func(c types.Container) {
-					options := types.ContainerLogsOptions{
-						ShowStdout: true,
-						ShowStderr: true,
-						Tail:       fmt.Sprintf("%d", tailCount),
-					}
-
-					out, err := DockerClient.ContainerLogs(ctx, c.ID, options)
-					if err != nil {
-						log.Printf("Error fetching logs for container %s: %v", strings.TrimPrefix(c.Names[0], "/"), err)
-						return
-					}
-					defer out.Close()
-
-					scanner := bufio.NewScanner(out)
-					var lines []string
-					var lineHashes []string
-					for scanner.Scan() {
-						line := scanner.Text()
-						lines = append(lines, line)
-						lineHashes = append(lineHashes, utils.HashString(line))
-					}
-					if err := scanner.Err(); err != nil {
-						log.Printf("Error scanning logs for container %s: %v", strings.TrimPrefix(c.Names[0], "/"), err)
-						return
-					}
-
-					storedMarker, exists := lastMarkers[c.ID]
-					startIndex := 0
-					if exists && storedMarker != "" {
-						found := false
-						for i, h := range lineHashes {
-							if h == storedMarker {
-								startIndex = i + 1
-								found = true
-								break
-							}
-						}
-						if !found {
-							startIndex = 0
-						}
-					}
-
-					if startIndex < len(lines) {
-						newLines := lines[startIndex:]
-						var errors []string
-						for _, line := range newLines {
-							if errorRegex.MatchString(line) {
-								errors = append(errors, line)
-							}
-						}
-						if len(errors) > 0 {
-							var errorMessages []string
-							for _, errLine := range errors[:utils.Min(3, len(errors))] {
-								filteredString := utils.RemoveControlCharactersRegex(strings.ToValidUTF8(errLine, ""))
-								escapedString := utils.EscapeHTML(filteredString)
-								errorMessages = append(errorMessages, fmt.Sprintf("<pre>%s</pre>", escapedString))
-							}
-
-							message := fmt.Sprintf(
-								"🚨 <b>Container <u>%s</u> encountered errors:</b>\n\n%s",
-								strings.TrimPrefix(c.Names[0], "/"),
-								strings.Join(errorMessages, "\n"),
-							)
-							log.Printf("Errors detected in container %s:\n%s",
-								strings.TrimPrefix(c.Names[0], "/"),
-								strings.Join(errors, "\n"),
-							)
-							notifier.SendText(telegramChatID, message)
-						}
-						lastMarkers[c.ID] = lineHashes[len(lineHashes)-1]
-					}
-				}(container)
+				message := fmt.Sprintf(
+					"🟢 <b>Crash loop of <u>%s</u> ended</b>\n\n"+
+						"<pre>"+
+						"┌ ID: %s\n"+
+						"├ Dies: %d\n"+
+						"└ Duration: %s"+
+						"</pre>",
+					loop.Name,
+					loop.ID[:12],
+					loop.Dies,
+					loop.Duration.Round(time.Second),
+				)
+				log.Printf("Crash loop ended: ID=%s, Name=%s", loop.ID[:12], loop.Name)
+				m.sendContainerAlert(loop.ID, loop.Name, "crashloop", message, false)
 			}
 		case <-ctx.Done():
 			return
 		}
 	}
 }
+
+func (m *eventMonitor) resolveRecoveredLoop(ctx context.Context, loop endedCrashLoop, now time.Time) bool {
+	inspectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	container, err := DockerClient.ContainerInspect(inspectCtx, loop.ID)
+	if err != nil || container.State == nil || !container.State.Running {
+		return false
+	}
+	m.resolveProblem(loop.ID, problemStopped, now)
+	return m.resolveProblem(loop.ID, problemCrashLoop, now)
+}
+
+func (m *eventMonitor) resolveProblem(id string, kind problemKind, at time.Time) bool {
+	problem, ok := m.problems.take(id, kind, at)
+	if !ok {
+		return false
+	}
+	if problem.Alert == nil {
+		return true
+	}
+	log.Printf("Resolving %s alert for container %s", kind, id[:12])
+	alert.Resolve(m.notifier, problem.Alert, at.Sub(problem.Since))
+	return true
+}
+
+func recordIncident(containerID, name string, kind incident.Kind, detail string, at time.Time) {
+	incident.Record(containerID, name, kind, detail, at)
+}
+
+func (m *eventMonitor) sendContainerAlert(containerID, name, subject, message string, critical bool) *alert.Tracked {
+	return sendContainerAlert(m.notifier, m.chatID, containerID, name, subject, message, critical)
+}
+
+func sendContainerAlert(notifier notification.Notifier, chatID int64, containerID, name, subject, message string, critical bool) *alert.Tracked {
+	if silence.IsSilenced(name, subject) {
+		log.Printf("Alert %q for container %s is silenced", subject, name)
+		return nil
+	}
+	return alert.Send(notifier, chatID, containerID, message, alert.EventKeyboard(containerID), critical)
+}
+
+func formatEventTimestamp(nano int64) string {
+	return fmt.Sprintf("%d.%09d", nano/int64(time.Second), nano%int64(time.Second))
+}
+
+func nextBackoff(current time.Duration) time.Duration {
+	next := current * 2
+	if next > eventsMaxBackoff {
+		return eventsMaxBackoff
+	}
+	return next
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,43 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+)
+
+func TestRecordDeathIgnoresRequestedStops(t *testing.T) {
+	tests := []struct {
+		name     string
+		sequence []string
+		want     crashLoopState
+	}{
+		{"manual restarts", []string{"kill", "die", "stop", "start", "kill", "die", "stop", "start", "kill", "die", "stop", "start"}, crashLoopNone},
+		{"crashes", []string{"die", "start", "die", "start", "die"}, crashLoopStarted},
+		{"crashes between restarts", []string{"die", "start", "kill", "die", "stop", "start", "die", "start", "die"}, crashLoopStarted},
+		{"restarts between crashes", []string{"die", "start", "kill", "die", "stop", "start", "die"}, crashLoopNone},
+	}
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &eventMonitor{crashLoops: newCrashLoopTracker(3, 10*time.Minute), stopRequests: newStopRequests()}
+			state := crashLoopNone
+			for i, status := range tt.sequence {
+				event := events.Message{
+					ID:       "abc",
+					Status:   status,
+					TimeNano: start.Add(time.Duration(i) * time.Second).UnixNano(),
+					Actor:    events.Actor{Attributes: map[string]string{"name": "api", "exitCode": "1"}},
+				}
+				requested := m.stopRequests.observe(event)
+				if status == "die" {
+					state, _ = m.recordDeath(event, requested)
+				}
+			}
+			if state != tt.want {
+				t.Errorf("last crash loop state = %v, want %v", state, tt.want)
+			}
+		})
+	}
+}
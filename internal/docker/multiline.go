@@ -0,0 +1,158 @@
+package docker
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+const maxEntryLines = 200
+
+type multilineMode int
+
+const (
+	modeDefault multilineMode = iota
+	modePython
+	modePythonTail
+	modeGo
+)
+
+var (
+	javaContinuation   = regexp.MustCompile(`^(Caused by:|Suppressed:|\.\.\. \d+ (more|common frames omitted))`)
+	pythonTraceback    = regexp.MustCompile(`^Traceback \(most recent call last\):`)
+	pythonChain        = regexp.MustCompile(`^(During handling of the above exception|The above exception was the direct cause)`)
+	goPanicHead        = regexp.MustCompile(`^(panic: |fatal error: |goroutine \d+ \[)`)
+	goFunctionLine     = regexp.MustCompile(`^[\w.\-/*()\[\]{}]+\(.*\)$`)
+	goDumpContinuation = regexp.MustCompile(`^(goroutine \d+ \[|\[signal |created by |exit status |panic: |\[recovered\])`)
+)
+
+type logEntry struct {
+	Lines []LogLine
+}
+
+func (e logEntry) Head() LogLine {
+	return e.Lines[0]
+}
+
+func (e logEntry) Last() LogLine {
+	return e.Lines[len(e.Lines)-1]
+}
+
+func (e logEntry) Text() string {
+	texts := make([]string, 0, len(e.Lines))
+	for _, line := range e.Lines {
+		texts = append(texts, line.Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+type pendingEntry struct {
+	entry     logEntry
+	mode      multilineMode
+	lastAdded time.Time
+}
+
+type multilineAggregator struct {
+	pending map[string]*pendingEntry
+}
+
+func newMultilineAggregator() *multilineAggregator {
+	return &multilineAggregator{pending: make(map[string]*pendingEntry)}
+}
+
+func (a *multilineAggregator) Add(line LogLine, now time.Time) []logEntry {
+	var completed []logEntry
+
+	pending := a.pending[line.Stream]
+	if pending != nil && len(pending.entry.Lines) < maxEntryLines {
+		if mode, ok := continues(pending.mode, line.Text); ok {
+			pending.entry.Lines = append(pending.entry.Lines, line)
+			pending.mode = mode
+			pending.lastAdded = now
+			return nil
+		}
+	}
+	if pending != nil {
+		completed = append(completed, finishEntry(pending.entry))
+	}
+
+	a.pending[line.Stream] = &pendingEntry{
+		entry:     logEntry{Lines: []LogLine{line}},
+		mode:      headMode(line.Text),
+		lastAdded: now,
+	}
+	return completed
+}
+
+func (a *multilineAggregator) Flush(idleBefore time.Time) []logEntry {
+	var completed []logEntry
+	for stream, pending := range a.pending {
+		if !idleBefore.IsZero() && pending.lastAdded.After(idleBefore) {
+			continue
+		}
+		completed = append(completed, finishEntry(pending.entry))
+		delete(a.pending, stream)
+	}
+	return completed
+}
+
+func groupLogLines(lines []LogLine) []logEntry {
+	aggregator := newMultilineAggregator()
+	var entries []logEntry
+	for _, line := range lines {
+		entries = append(entries, aggregator.Add(line, time.Time{})...)
+	}
+	return append(entries, aggregator.Flush(time.Time{})...)
+}
+
+func headMode(text string) multilineMode {
+	switch {
+	case pythonTraceback.MatchString(text):
+		return modePython
+	case goPanicHead.MatchString(text):
+		return modeGo
+	}
+	return modeDefault
+}
+
+func continues(mode multilineMode, text string) (multilineMode, bool) {
+	indented := strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")
+	blank := strings.TrimSpace(text) == ""
+
+	if pythonTraceback.MatchString(text) {
+		return modePython, true
+	}
+
+	switch mode {
+	case modePython:
+		if indented || blank {
+			return modePython, true
+		}
+		return modePythonTail, true
+	case modePythonTail:
+		if blank || pythonChain.MatchString(text) {
+			return modePythonTail, true
+		}
+		return mode, false
+	case modeGo:
+		if indented || blank || goDumpContinuation.MatchString(text) || goFunctionLine.MatchString(text) {
+			return modeGo, true
+		}
+		return mode, false
+	}
+
+	if indented && !blank {
+		return modeDefault, true
+	}
+	if javaContinuation.MatchString(text) {
+		return modeDefault, true
+	}
+	return mode, false
+}
+
+func finishEntry(entry logEntry) logEntry {
+	for len(entry.Lines) > 1 && strings.TrimSpace(entry.Last().Text) == "" {
+		entry.Lines = entry.Lines[:len(entry.Lines)-1]
+	}
+	return entry
+}
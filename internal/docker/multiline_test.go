@@ -0,0 +1,133 @@
+package docker
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestGroupLogLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "single lines",
+			lines: []string{"starting", "ERROR: failed", "ready"},
+			want:  []string{"starting", "ERROR: failed", "ready"},
+		},
+		{
+			name: "java stack trace",
+			lines: []string{
+				"Exception in thread \"main\" java.lang.IllegalStateException: boom",
+				"\tat com.example.App.run(App.java:10)",
+				"\tat com.example.App.main(App.java:5)",
+				"Caused by: java.io.IOException: disk full",
+				"\tat com.example.Store.write(Store.java:42)",
+				"\t... 2 more",
+				"next line",
+			},
+			want: []string{
+				"Exception in thread \"main\" java.lang.IllegalStateException: boom\n\tat com.example.App.run(App.java:10)\n\tat com.example.App.main(App.java:5)\nCaused by: java.io.IOException: disk full\n\tat com.example.Store.write(Store.java:42)\n\t... 2 more",
+				"next line",
+			},
+		},
+		{
+			name: "python chained traceback",
+			lines: []string{
+				"Traceback (most recent call last):",
+				"  File \"app.py\", line 3, in <module>",
+				"    main()",
+				"KeyError: 'id'",
+				"",
+				"During handling of the above exception, another exception occurred:",
+				"",
+				"Traceback (most recent call last):",
+				"  File \"app.py\", line 5, in <module>",
+				"ValueError: bad id",
+				"INFO worker stopped",
+			},
+			want: []string{
+				"Traceback (most recent call last):\n  File \"app.py\", line 3, in <module>\n    main()\nKeyError: 'id'\n\nDuring handling of the above exception, another exception occurred:\n\nTraceback (most recent call last):\n  File \"app.py\", line 5, in <module>\nValueError: bad id",
+				"INFO worker stopped",
+			},
+		},
+		{
+			name: "go panic",
+			lines: []string{
+				"panic: runtime error: invalid memory address or nil pointer dereference",
+				"[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x4a1b2c]",
+				"",
+				"goroutine 1 [running]:",
+				"main.handler(0x0)",
+				"\t/app/main.go:12 +0x1d",
+				"main.main()",
+				"\t/app/main.go:7 +0x25",
+				"exit status 2",
+				"restarting",
+			},
+			want: []string{
+				"panic: runtime error: invalid memory address or nil pointer dereference\n[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x4a1b2c]\n\ngoroutine 1 [running]:\nmain.handler(0x0)\n\t/app/main.go:12 +0x1d\nmain.main()\n\t/app/main.go:7 +0x25\nexit status 2",
+				"restarting",
+			},
+		},
+		{
+			name:  "trailing blank lines are dropped",
+			lines: []string{"Traceback (most recent call last):", "  File \"x.py\", line 1", "OSError: gone", "", ""},
+			want:  []string{"Traceback (most recent call last):\n  File \"x.py\", line 1\nOSError: gone"},
+		},
+		{
+			name:  "blank line does not continue a plain line",
+			lines: []string{"first", "", "second"},
+			want:  []string{"first", "", "second"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []LogLine
+			for _, text := range tt.lines {
+				lines = append(lines, LogLine{Stream: "stdout", Text: text})
+			}
+			var got []string
+			for _, entry := range groupLogLines(lines) {
+				got = append(got, entry.Text())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupLogLinesKeepsStreamsApart(t *testing.T) {
+	lines := []LogLine{
+		{Stream: "stderr", Text: "java.lang.RuntimeException: boom"},
+		{Stream: "stdout", Text: "  indented stdout line"},
+		{Stream: "stderr", Text: "\tat com.example.App.run(App.java:10)"},
+	}
+	var got []string
+	for _, entry := range groupLogLines(lines) {
+		got = append(got, entry.Head().Stream+": "+strings.ReplaceAll(entry.Text(), "\n", " | "))
+	}
+	sort.Strings(got)
+	want := []string{
+		"stderr: java.lang.RuntimeException: boom | \tat com.example.App.run(App.java:10)",
+		"stdout:   indented stdout line",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestGroupLogLinesLimitsEntrySize(t *testing.T) {
+	lines := []LogLine{{Stream: "stdout", Text: "java.lang.RuntimeException: boom"}}
+	for i := 0; i < maxEntryLines+5; i++ {
+		lines = append(lines, LogLine{Stream: "stdout", Text: "\tat frame"})
+	}
+	entries := groupLogLines(lines)
+	if len(entries) != 2 || len(entries[0].Lines) != maxEntryLines || len(entries[1].Lines) != 6 {
+		t.Errorf("got %d entries, want the first one capped at %d lines", len(entries), maxEntryLines)
+	}
+}
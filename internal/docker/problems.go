@@ -0,0 +1,106 @@
+package docker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/alert"
+)
+
+type problemKind string
+
+const (
+	problemStopped   problemKind = "stopped"
+	problemCrashLoop problemKind = "crashloop"
+	problemUnhealthy problemKind = "unhealthy"
+)
+
+type openProblem struct {
+	Alert      *alert.Tracked
+	Since      time.Time
+	pending    bool
+	resolvedAt time.Time
+}
+
+type problemTracker struct {
+	mu         sync.Mutex
+	containers map[string]map[problemKind]openProblem
+}
+
+func newProblemTracker() *problemTracker {
+	return &problemTracker{containers: make(map[string]map[problemKind]openProblem)}
+}
+
+func (t *problemTracker) open(id string, kind problemKind, tracked *alert.Tracked, since time.Time) {
+	if tracked == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	problems, ok := t.containers[id]
+	if !ok {
+		problems = make(map[problemKind]openProblem)
+		t.containers[id] = problems
+	}
+	problems[kind] = openProblem{Alert: tracked, Since: since}
+}
+
+func (t *problemTracker) expect(id string, kind problemKind, since time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	problems, ok := t.containers[id]
+	if !ok {
+		problems = make(map[problemKind]openProblem)
+		t.containers[id] = problems
+	}
+	problems[kind] = openProblem{Since: since, pending: true}
+}
+
+func (t *problemTracker) fulfil(id string, kind problemKind, tracked *alert.Tracked) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	problem, ok := t.containers[id][kind]
+	if !ok || !problem.pending {
+		return time.Time{}, false
+	}
+	if tracked == nil || !problem.resolvedAt.IsZero() {
+		t.deleteLocked(id, kind)
+		return problem.resolvedAt, !problem.resolvedAt.IsZero()
+	}
+	t.containers[id][kind] = openProblem{Alert: tracked, Since: problem.Since}
+	return time.Time{}, false
+}
+
+func (t *problemTracker) take(id string, kind problemKind, at time.Time) (openProblem, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	problem, ok := t.containers[id][kind]
+	if !ok {
+		return openProblem{}, false
+	}
+	if problem.pending {
+		problem.resolvedAt = at
+		t.containers[id][kind] = problem
+		return problem, true
+	}
+	t.deleteLocked(id, kind)
+	return problem, true
+}
+
+func (t *problemTracker) deleteLocked(id string, kind problemKind) {
+	delete(t.containers[id], kind)
+	if len(t.containers[id]) == 0 {
+		delete(t.containers, id)
+	}
+}
+
+func (t *problemTracker) forget(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.containers, id)
+}
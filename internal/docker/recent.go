@@ -0,0 +1,50 @@
+package docker
+
+import "sync"
+
+type recentLines struct {
+	mu         sync.Mutex
+	size       int
+	containers map[string][]string
+}
+
+func newRecentLines(size int) *recentLines {
+	return &recentLines{size: size, containers: make(map[string][]string)}
+}
+
+func (r *recentLines) get(id string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.containers[id]...)
+}
+
+func (r *recentLines) push(id string, lines []string) {
+	if r.size <= 0 || len(lines) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buffered := append(r.containers[id], lines...)
+	if len(buffered) > r.size {
+		buffered = append([]string(nil), buffered[len(buffered)-r.size:]...)
+	}
+	r.containers[id] = buffered
+}
+
+func (r *recentLines) forget(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.containers, id)
+}
+
+func (r *recentLines) retain(containerIDs map[string]bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id := range r.containers {
+		if !containerIDs[id] {
+			delete(r.containers, id)
+		}
+	}
+}
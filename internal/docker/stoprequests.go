@@ -0,0 +1,32 @@
+package docker
+
+import (
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+)
+
+const stopRequestWindow = time.Minute
+
+type stopRequests struct {
+	requested map[string]time.Time
+}
+
+func newStopRequests() *stopRequests {
+	return &stopRequests{requested: make(map[string]time.Time)}
+}
+
+func (r *stopRequests) observe(event events.Message) bool {
+	at := time.Unix(0, event.TimeNano)
+	switch event.Status {
+	case "kill":
+		r.requested[event.ID] = at
+	case "start", "destroy":
+		delete(r.requested, event.ID)
+	case "die":
+		requestedAt, ok := r.requested[event.ID]
+		delete(r.requested, event.ID)
+		return ok && at.Sub(requestedAt) <= stopRequestWindow
+	}
+	return false
+}
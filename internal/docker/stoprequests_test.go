@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+)
+
+func TestStopRequests(t *testing.T) {
+	type step struct {
+		status    string
+		after     time.Duration
+		requested bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "restart then crash",
+			steps: []step{
+				{"kill", 0, false},
+				{"die", time.Second, true},
+				{"stop", time.Second, false},
+				{"start", 2 * time.Second, false},
+				{"die", time.Hour, false},
+			},
+		},
+		{
+			name: "second die without a kill",
+			steps: []step{
+				{"kill", 0, false},
+				{"kill", 10 * time.Second, false},
+				{"die", 11 * time.Second, true},
+				{"stop", 11 * time.Second, false},
+				{"die", time.Hour, false},
+			},
+		},
+		{
+			name:  "crash without kill",
+			steps: []step{{"die", 0, false}},
+		},
+		{
+			name: "kill long before die",
+			steps: []step{
+				{"kill", 0, false},
+				{"die", stopRequestWindow + time.Second, false},
+			},
+		},
+	}
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := newStopRequests()
+			for i, s := range tt.steps {
+				event := events.Message{ID: "abc", Status: s.status, TimeNano: start.Add(s.after).UnixNano()}
+				if got := requests.observe(event); got != s.requested {
+					t.Errorf("step %d (%s): requested = %v, want %v", i, s.status, got, s.requested)
+				}
+			}
+		})
+	}
+}
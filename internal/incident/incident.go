@@ -0,0 +1,257 @@
+package incident
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/storage"
+)
+
+const (
+	incidentsFileName = "incidents.json"
+
+	quietPeriod     = 10 * time.Minute
+	downQuietPeriod = 24 * time.Hour
+	flushInterval   = 5 * time.Second
+	maxIncidents    = 200
+	maxEvents       = 100
+)
+
+type Kind string
+
+const (
+	KindDie       Kind = "die"
+	KindOOM       Kind = "oom"
+	KindCrashLoop Kind = "crashloop"
+	KindUnhealthy Kind = "unhealthy"
+	KindErrors    Kind = "errors"
+	KindRestart   Kind = "restart"
+	KindStart     Kind = "start"
+	KindHealthy   Kind = "healthy"
+	KindAction    Kind = "action"
+)
+
+func (k Kind) Icon() string {
+	switch k {
+	case KindDie:
+		return "❗️"
+	case KindOOM:
+		return "💥"
+	case KindCrashLoop:
+		return "🔁"
+	case KindUnhealthy:
+		return "💔"
+	case KindErrors:
+		return "🔥"
+	case KindRestart:
+		return "🩹"
+	case KindStart:
+		return "🚀"
+	case KindHealthy:
+		return "💚"
+	case KindAction:
+		return "👤"
+	}
+	return "•"
+}
+
+func (k Kind) opens() bool {
+	switch k {
+	case KindDie, KindOOM, KindCrashLoop, KindUnhealthy, KindErrors:
+		return true
+	}
+	return false
+}
+
+type Event struct {
+	At     time.Time `json:"at"`
+	Kind   Kind      `json:"kind"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+type Incident struct {
+	ID          string    `json:"id"`
+	ContainerID string    `json:"container_id"`
+	Container   string    `json:"container"`
+	StartedAt   time.Time `json:"started_at"`
+	EndedAt     time.Time `json:"ended_at,omitempty"`
+	Down        bool      `json:"down"`
+	Events      []Event   `json:"events"`
+	Dropped     int       `json:"dropped,omitempty"`
+}
+
+func (i *Incident) Open() bool {
+	return i.EndedAt.IsZero()
+}
+
+func (i *Incident) LastEventAt() time.Time {
+	return i.Events[len(i.Events)-1].At
+}
+
+type persistedState struct {
+	NextID    int         `json:"next_id"`
+	Incidents []*Incident `json:"incidents"`
+}
+
+var (
+	mu       sync.Mutex
+	filePath string
+	state    = persistedState{NextID: 1}
+	dirty    bool
+)
+
+func Init(stateDir string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	filePath = filepath.Join(stateDir, incidentsFileName)
+	return storage.LoadJSON(filePath, &state)
+}
+
+func Run(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			mu.Lock()
+			if closeIdleLocked(now) {
+				dirty = true
+			}
+			flushLocked()
+			mu.Unlock()
+		case <-ctx.Done():
+			mu.Lock()
+			flushLocked()
+			mu.Unlock()
+			return
+		}
+	}
+}
+
+func Record(containerID, container string, kind Kind, detail string, at time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	closeIdleLocked(time.Now())
+
+	current := openLocked(containerID)
+	if current == nil {
+		if !kind.opens() {
+			return
+		}
+		current = &Incident{
+			ID:          strconv.Itoa(state.NextID),
+			ContainerID: containerID,
+			Container:   container,
+			StartedAt:   at,
+		}
+		state.NextID++
+		state.Incidents = append(state.Incidents, current)
+		if len(state.Incidents) > maxIncidents {
+			state.Incidents = state.Incidents[len(state.Incidents)-maxIncidents:]
+		}
+	}
+
+	if len(current.Events) >= maxEvents {
+		current.Events = append(current.Events[:1], current.Events[2:]...)
+		current.Dropped++
+	}
+	current.Events = append(current.Events, Event{At: at, Kind: kind, Detail: detail})
+	switch kind {
+	case KindDie, KindOOM, KindCrashLoop, KindUnhealthy:
+		current.Down = true
+	case KindStart, KindHealthy:
+		current.Down = false
+	}
+	dirty = true
+}
+
+func Close(containerID string, at time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	current := openLocked(containerID)
+	if current == nil {
+		return
+	}
+	current.EndedAt = at
+	dirty = true
+}
+
+func List() []Incident {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if closeIdleLocked(time.Now()) {
+		dirty = true
+	}
+	incidents := make([]Incident, 0, len(state.Incidents))
+	for _, incident := range state.Incidents {
+		incidents = append(incidents, incident.copy())
+	}
+	sort.SliceStable(incidents, func(i, j int) bool { return incidents[i].StartedAt.After(incidents[j].StartedAt) })
+	return incidents
+}
+
+func Get(id string) (Incident, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if closeIdleLocked(time.Now()) {
+		dirty = true
+	}
+	for _, incident := range state.Incidents {
+		if incident.ID == id {
+			return incident.copy(), true
+		}
+	}
+	return Incident{}, false
+}
+
+func (i *Incident) copy() Incident {
+	c := *i
+	c.Events = append([]Event(nil), i.Events...)
+	return c
+}
+
+func openLocked(containerID string) *Incident {
+	for i := len(state.Incidents) - 1; i >= 0; i-- {
+		if incident := state.Incidents[i]; incident.ContainerID == containerID && incident.Open() {
+			return incident
+		}
+	}
+	return nil
+}
+
+func closeIdleLocked(now time.Time) bool {
+	closed := false
+	for _, incident := range state.Incidents {
+		period := quietPeriod
+		if incident.Down {
+			period = downQuietPeriod
+		}
+		if incident.Open() && now.Sub(incident.LastEventAt()) >= period {
+			incident.EndedAt = incident.LastEventAt()
+			closed = true
+		}
+	}
+	return closed
+}
+
+func flushLocked() {
+	if !dirty || filePath == "" {
+		return
+	}
+	if err := storage.SaveJSON(filePath, state); err != nil {
+		log.Printf("Error saving incidents: %v", err)
+		return
+	}
+	dirty = false
+}
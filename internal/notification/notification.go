@@ -7,8 +7,11 @@ import (
 type Notifier interface {
 	SendText(chatID int64, message string) int
 	SendTextWithKeyboard(chatID int64, message string, keyboard tgbotapi.InlineKeyboardMarkup) int
+	SendDocument(chatID int64, fileName string, data []byte, caption string) int
+	SendDocumentWithKeyboard(chatID int64, fileName string, data []byte, caption string, keyboard tgbotapi.InlineKeyboardMarkup) int
 	EditMessageText(chatID int64, messageID int, text string)
 	EditMessageWithKeyboard(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup)
+	EditMessageCaptionWithKeyboard(chatID int64, messageID int, caption string, keyboard tgbotapi.InlineKeyboardMarkup)
 	AnswerCallbackQuery(callbackID string, text string)
 	DeleteMessage(chatID int64, messageID int)
 }
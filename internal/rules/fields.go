@@ -0,0 +1,94 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var fieldOperators = []string{">=", "<=", "!=", "=", ">", "<", "~"}
+
+type fieldCondition struct {
+	key      string
+	operator string
+	value    string
+	number   float64
+	numeric  bool
+	regex    *regexp.Regexp
+}
+
+func parseFieldCondition(expr string) (fieldCondition, error) {
+	for i := 1; i < len(expr); i++ {
+		for _, operator := range fieldOperators {
+			if !strings.HasPrefix(expr[i:], operator) {
+				continue
+			}
+			condition := fieldCondition{
+				key:      strings.TrimSpace(expr[:i]),
+				operator: operator,
+				value:    strings.TrimSpace(expr[i+len(operator):]),
+			}
+			if condition.key == "" || strings.ContainsAny(condition.key, "<>!=~") {
+				break
+			}
+
+			switch operator {
+			case "~":
+				re, err := regexp.Compile(condition.value)
+				if err != nil {
+					return condition, fmt.Errorf("invalid pattern in field condition %q: %v", expr, err)
+				}
+				condition.regex = re
+			case ">=", "<=", ">", "<":
+				number, err := strconv.ParseFloat(condition.value, 64)
+				if err != nil {
+					return condition, fmt.Errorf("field condition %q needs a numeric value", expr)
+				}
+				condition.number = number
+				condition.numeric = true
+			default:
+				if number, err := strconv.ParseFloat(condition.value, 64); err == nil {
+					condition.number = number
+					condition.numeric = true
+				}
+			}
+			return condition, nil
+		}
+	}
+	return fieldCondition{}, fmt.Errorf("invalid field condition %q, expected e.g. status>=500", expr)
+}
+
+func (c fieldCondition) matches(fields map[string]interface{}) bool {
+	value, ok := lookupField(fields, c.key)
+	if !ok {
+		return false
+	}
+
+	switch c.operator {
+	case "~":
+		return c.regex.MatchString(fieldString(value))
+	case "=", "!=":
+		equal := fieldString(value) == c.value
+		if number, isNumber := fieldNumber(value); isNumber && c.numeric {
+			equal = number == c.number
+		}
+		return equal == (c.operator == "=")
+	}
+
+	number, isNumber := fieldNumber(value)
+	if !isNumber {
+		return false
+	}
+	switch c.operator {
+	case ">=":
+		return number >= c.number
+	case "<=":
+		return number <= c.number
+	case ">":
+		return number > c.number
+	case "<":
+		return number < c.number
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package rules
+
+import "testing"
+
+func TestFieldConditions(t *testing.T) {
+	options := JSONOptions{LevelFields: []string{"level"}, MessageFields: []string{"msg"}}
+	tests := []struct {
+		condition string
+		raw       string
+		want      bool
+	}{
+		{"status>=500", `{"status":503}`, true},
+		{"status>=500", `{"status":500}`, true},
+		{"status>=500", `{"status":404}`, false},
+		{"status>=500", `{"status":"502"}`, true},
+		{"status>=500", `{"status":"bad gateway"}`, false},
+		{"status>=500", `{"code":503}`, false},
+		{"status<400", `{"status":200}`, true},
+		{"latency_ms>1000", `{"latency_ms":1000.5}`, true},
+		{"latency_ms<=1000", `{"latency_ms":1000}`, true},
+		{"status=500", `{"status":500.0}`, true},
+		{"status!=200", `{"status":200}`, false},
+		{"method=POST", `{"method":"POST"}`, true},
+		{"method!=GET", `{"method":"POST"}`, true},
+		{"http.status>=500", `{"http":{"status":502}}`, true},
+		{"http.status>=500", `{"http.status":502}`, true},
+		{"error~(?i)timeout", `{"error":"Read TIMEOUT"}`, true},
+		{"error~(?i)timeout", `{"error":"refused"}`, false},
+		{"status>=500", `status=503`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition+" "+tt.raw, func(t *testing.T) {
+			engine, err := NewEngine([]Rule{{Name: "fields", Fields: []string{tt.condition}}})
+			if err != nil {
+				t.Fatalf("NewEngine: %v", err)
+			}
+			_, got := engine.Match(Target{Name: "api"}, options.Parse("stdout", tt.raw))
+			if got != tt.want {
+				t.Errorf("matched = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFieldConditionErrors(t *testing.T) {
+	for _, expr := range []string{"status", ">=500", "status>=abc", "error~("} {
+		if _, err := parseFieldCondition(expr); err == nil {
+			t.Errorf("parseFieldCondition(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestJSONLevels(t *testing.T) {
+	options := JSONOptions{LevelFields: []string{"level", "severity"}, MessageFields: []string{"msg", "message"}}
+	tests := []struct {
+		raw   string
+		level Severity
+		text  string
+	}{
+		{`{"level":"error","msg":"db down"}`, SeverityError, "db down"},
+		{`{"level":"WARN","message":"slow query"}`, SeverityWarning, "slow query"},
+		{`{"severity":"fatal","msg":"exit"}`, SeverityCritical, "exit"},
+		{`{"level":50,"msg":"pino error"}`, SeverityError, "pino error"},
+		{`{"level":30,"msg":"pino info"}`, SeverityInfo, "pino info"},
+		{`{"level":"verbose","msg":"custom"}`, "", "custom"},
+		{`not json`, "", "not json"},
+	}
+	for _, tt := range tests {
+		line := options.Parse("stdout", tt.raw)
+		if line.Level != tt.level || line.Text != tt.text {
+			t.Errorf("Parse(%s) = %q/%q, want %q/%q", tt.raw, line.Level, line.Text, tt.level, tt.text)
+		}
+	}
+}
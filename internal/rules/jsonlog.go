@@ -0,0 +1,156 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var timeFields = map[string]bool{"time": true, "ts": true, "timestamp": true, "@timestamp": true}
+
+type JSONOptions struct {
+	LevelFields   []string
+	MessageFields []string
+	DisplayFields []string
+}
+
+func (o JSONOptions) Parse(stream, raw string) Line {
+	line := Line{Stream: stream, Text: raw}
+
+	trimmed := strings.TrimSpace(raw)
+	if !strings.HasPrefix(trimmed, "{") || !strings.HasSuffix(trimmed, "}") {
+		return line
+	}
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return line
+	}
+
+	line.Fields = fields
+	for _, key := range o.LevelFields {
+		if value, ok := lookupField(fields, key); ok {
+			line.LevelName = fieldString(value)
+			line.Level = levelSeverity(value)
+			break
+		}
+	}
+	for _, key := range o.MessageFields {
+		if value, ok := lookupField(fields, key); ok {
+			line.Text = fieldString(value)
+			break
+		}
+	}
+	return line
+}
+
+func (o JSONOptions) KeyFields(line Line) []string {
+	skip := make(map[string]bool)
+	for _, key := range append(append([]string{}, o.LevelFields...), o.MessageFields...) {
+		skip[key] = true
+	}
+
+	keys := o.DisplayFields
+	if len(keys) == 0 {
+		for key, value := range line.Fields {
+			if skip[key] || timeFields[key] {
+				continue
+			}
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				continue
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+
+	var rendered []string
+	for _, key := range keys {
+		if len(rendered) == 8 {
+			break
+		}
+		if value, ok := lookupField(line.Fields, key); ok {
+			rendered = append(rendered, fmt.Sprintf("%s=%s", key, fieldString(value)))
+		}
+	}
+	return rendered
+}
+
+func lookupField(fields map[string]interface{}, key string) (interface{}, bool) {
+	if value, ok := fields[key]; ok {
+		return value, true
+	}
+
+	var current interface{} = fields
+	for _, part := range strings.Split(key, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func fieldString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return "null"
+	case map[string]interface{}, []interface{}:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func fieldNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func levelSeverity(value interface{}) Severity {
+	if number, ok := value.(json.Number); ok {
+		level, err := number.Int64()
+		if err != nil {
+			return ""
+		}
+		switch {
+		case level >= 60:
+			return SeverityCritical
+		case level >= 50:
+			return SeverityError
+		case level >= 40:
+			return SeverityWarning
+		}
+		return SeverityInfo
+	}
+
+	switch strings.ToLower(fieldString(value)) {
+	case "fatal", "panic", "critical", "crit", "alert", "emerg", "emergency", "dpanic":
+		return SeverityCritical
+	case "error", "err", "eror":
+		return SeverityError
+	case "warn", "warning":
+		return SeverityWarning
+	case "info", "information", "notice", "debug", "trace":
+		return SeverityInfo
+	}
+	return ""
+}
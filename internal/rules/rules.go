@@ -0,0 +1,292 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/storage"
+)
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityError    Severity = "error"
+	SeverityCritical Severity = "critical"
+)
+
+const ComposeServiceLabel = "com.docker.compose.service"
+
+var severityRanks = map[Severity]int{
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityError:    3,
+	SeverityCritical: 4,
+}
+
+func (s Severity) Rank() int {
+	return severityRanks[s]
+}
+
+func (s Severity) Icon() string {
+	switch s {
+	case SeverityInfo:
+		return "ℹ️"
+	case SeverityWarning:
+		return "⚠️"
+	case SeverityCritical:
+		return "🔥"
+	}
+	return "🚨"
+}
+
+type Scope struct {
+	Images     []string `json:"images,omitempty"`
+	Services   []string `json:"services,omitempty"`
+	Containers []string `json:"containers,omitempty"`
+}
+
+type Rule struct {
+	Name     string   `json:"name"`
+	Severity Severity `json:"severity"`
+	Include  []string `json:"include,omitempty"`
+	Exclude  []string `json:"exclude,omitempty"`
+	Streams  []string `json:"streams,omitempty"`
+	Fields   []string `json:"fields,omitempty"`
+	Scope    Scope    `json:"scope,omitempty"`
+}
+
+type Target struct {
+	Name    string
+	Image   string
+	Service string
+}
+
+type Line struct {
+	Stream    string
+	Text      string
+	Fields    map[string]interface{}
+	Level     Severity
+	LevelName string
+}
+
+type Match struct {
+	Rule     string
+	Severity Severity
+}
+
+type compiledRule struct {
+	Rule
+	include    []*regexp.Regexp
+	exclude    []*regexp.Regexp
+	conditions []fieldCondition
+}
+
+type Engine struct {
+	rules []compiledRule
+}
+
+type ruleFile struct {
+	Rules []Rule `json:"rules"`
+}
+
+func DefaultRules(stderrIsError bool) []Rule {
+	defaults := []Rule{
+		{
+			Name:     "error",
+			Severity: SeverityError,
+			Include:  []string{`(?i)\berror\b`, `(?i)\bexception\b`, `Traceback \(most recent call last\)`},
+			Exclude:  []string{`(?i)\b(0|no|zero) errors?\b`},
+		},
+		{
+			Name:     "fatal",
+			Severity: SeverityCritical,
+			Include:  []string{`(?i)\bfatal\b`, `(?i)\bpanic\b`},
+		},
+	}
+	if stderrIsError {
+		defaults = append(defaults, stderrRule())
+	}
+	return defaults
+}
+
+func stderrRule() Rule {
+	return Rule{
+		Name:     "stderr",
+		Severity: SeverityError,
+		Streams:  []string{"stderr"},
+	}
+}
+
+func Load(filePath string, stderrIsError bool) (*Engine, error) {
+	if filePath == "" {
+		return NewEngine(DefaultRules(stderrIsError))
+	}
+
+	var file ruleFile
+	if err := storage.LoadJSON(filePath, &file); err != nil {
+		return nil, err
+	}
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("no rules defined in %s", filePath)
+	}
+	if stderrIsError {
+		file.Rules = append(file.Rules, stderrRule())
+	}
+	return NewEngine(file.Rules)
+}
+
+func NewEngine(rules []Rule) (*Engine, error) {
+	engine := &Engine{}
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule #%d has no name", i+1)
+		}
+		if rule.Severity == "" {
+			rule.Severity = SeverityError
+		}
+		if rule.Severity.Rank() == 0 {
+			return nil, fmt.Errorf("rule %q has invalid severity %q", rule.Name, rule.Severity)
+		}
+		if len(rule.Include) == 0 && len(rule.Streams) == 0 && len(rule.Fields) == 0 {
+			return nil, fmt.Errorf("rule %q needs at least one include pattern, stream or field condition", rule.Name)
+		}
+
+		for _, pattern := range append(append(append([]string{}, rule.Scope.Images...), rule.Scope.Services...), rule.Scope.Containers...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("rule %q has invalid scope pattern %q: %v", rule.Name, pattern, err)
+			}
+		}
+
+		compiled := compiledRule{Rule: rule}
+		for _, pattern := range rule.Include {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q has invalid include pattern %q: %v", rule.Name, pattern, err)
+			}
+			compiled.include = append(compiled.include, re)
+		}
+		for _, pattern := range rule.Exclude {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q has invalid exclude pattern %q: %v", rule.Name, pattern, err)
+			}
+			compiled.exclude = append(compiled.exclude, re)
+		}
+		for _, expr := range rule.Fields {
+			condition, err := parseFieldCondition(expr)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: %v", rule.Name, err)
+			}
+			compiled.conditions = append(compiled.conditions, condition)
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+	return engine, nil
+}
+
+func (e *Engine) Match(target Target, line Line) (Match, bool) {
+	var best Match
+	found := false
+	if line.Level.Rank() >= SeverityError.Rank() {
+		best = Match{Rule: "json-level", Severity: line.Level}
+		found = true
+	}
+	for _, rule := range e.rules {
+		if !rule.appliesTo(target) || !rule.matches(line) {
+			continue
+		}
+		if !found || rule.Severity.Rank() > best.Severity.Rank() {
+			best = Match{Rule: rule.Name, Severity: rule.Severity}
+			found = true
+		}
+	}
+	return best, found
+}
+
+func (r *compiledRule) appliesTo(target Target) bool {
+	scope := r.Scope
+	if len(scope.Images) > 0 && !matchesAny(scope.Images, target.Image, imageName(target.Image)) {
+		return false
+	}
+	if len(scope.Services) > 0 && !matchesAny(scope.Services, target.Service) {
+		return false
+	}
+	if len(scope.Containers) > 0 && !matchesAny(scope.Containers, target.Name) {
+		return false
+	}
+	return true
+}
+
+func (r *compiledRule) matches(line Line) bool {
+	if len(r.conditions) > 0 {
+		if line.Fields == nil {
+			return false
+		}
+		for _, condition := range r.conditions {
+			if !condition.matches(line.Fields) {
+				return false
+			}
+		}
+	}
+
+	if len(r.Streams) > 0 {
+		streamMatched := false
+		for _, stream := range r.Streams {
+			if stream == line.Stream {
+				streamMatched = true
+				break
+			}
+		}
+		if !streamMatched {
+			return false
+		}
+	}
+
+	if len(r.include) > 0 {
+		included := false
+		for _, re := range r.include {
+			if re.MatchString(line.Text) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, re := range r.exclude {
+		if re.MatchString(line.Text) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(patterns []string, values ...string) bool {
+	for _, pattern := range patterns {
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			if ok, err := path.Match(pattern, value); err == nil && ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func imageName(image string) string {
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
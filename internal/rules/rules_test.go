@@ -0,0 +1,115 @@
+package rules
+
+import "testing"
+
+func TestEngineMatch(t *testing.T) {
+	engine, err := NewEngine([]Rule{
+		{
+			Name:     "error",
+			Severity: SeverityError,
+			Include:  []string{`(?i)\berror\b`},
+			Exclude:  []string{`(?i)\b(0|no) errors?\b`},
+		},
+		{
+			Name:     "fatal",
+			Severity: SeverityCritical,
+			Include:  []string{`(?i)\bfatal\b`},
+		},
+		{
+			Name:     "nginx-upstream",
+			Severity: SeverityWarning,
+			Include:  []string{`upstream timed out`},
+			Scope:    Scope{Images: []string{"nginx"}},
+		},
+		{
+			Name:     "worker-retry",
+			Severity: SeverityInfo,
+			Include:  []string{`retrying`},
+			Scope:    Scope{Services: []string{"worker"}, Containers: []string{"app-worker-*"}},
+		},
+		{
+			Name:     "stderr",
+			Severity: SeverityWarning,
+			Streams:  []string{"stderr"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+
+	nginx := Target{Name: "proxy", Image: "nginx:1.25"}
+	worker := Target{Name: "app-worker-1", Image: "app:latest", Service: "worker"}
+	other := Target{Name: "db", Image: "postgres:16"}
+
+	tests := []struct {
+		name     string
+		target   Target
+		line     Line
+		matched  bool
+		rule     string
+		severity Severity
+	}{
+		{"include", other, Line{Stream: "stdout", Text: "ERROR: connection refused"}, true, "error", SeverityError},
+		{"no match", other, Line{Stream: "stdout", Text: "ready to accept connections"}, false, "", ""},
+		{"exclude", other, Line{Stream: "stdout", Text: "migration finished with 0 errors"}, false, "", ""},
+		{"highest severity wins", other, Line{Stream: "stdout", Text: "fatal error: out of memory"}, true, "fatal", SeverityCritical},
+		{"image scope without tag", nginx, Line{Stream: "stdout", Text: "upstream timed out while reading"}, true, "nginx-upstream", SeverityWarning},
+		{"image scope other image", other, Line{Stream: "stdout", Text: "upstream timed out while reading"}, false, "", ""},
+		{"service and container scope", worker, Line{Stream: "stdout", Text: "job failed, retrying"}, true, "worker-retry", SeverityInfo},
+		{"scope requires every field", Target{Name: "other-worker", Service: "worker"}, Line{Stream: "stdout", Text: "job failed, retrying"}, false, "", ""},
+		{"stream", other, Line{Stream: "stderr", Text: "deprecated option"}, true, "stderr", SeverityWarning},
+		{"stream and text", other, Line{Stream: "stderr", Text: "error opening file"}, true, "error", SeverityError},
+		{"json level", other, Line{Stream: "stdout", Text: "request failed", Level: SeverityCritical}, true, "json-level", SeverityCritical},
+		{"json level below error", other, Line{Stream: "stdout", Text: "cache miss", Level: SeverityWarning}, false, "", ""},
+		{"json level and text rule", other, Line{Stream: "stdout", Text: "fatal: disk full", Level: SeverityError}, true, "fatal", SeverityCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, ok := engine.Match(tt.target, tt.line)
+			if ok != tt.matched {
+				t.Fatalf("matched = %v, want %v (%+v)", ok, tt.matched, match)
+			}
+			if ok && (match.Rule != tt.rule || match.Severity != tt.severity) {
+				t.Errorf("got %s/%s, want %s/%s", match.Rule, match.Severity, tt.rule, tt.severity)
+			}
+		})
+	}
+}
+
+func TestNewEngineErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"missing name", Rule{Include: []string{"error"}}},
+		{"invalid severity", Rule{Name: "r", Severity: "loud", Include: []string{"error"}}},
+		{"nothing to match", Rule{Name: "r"}},
+		{"invalid include", Rule{Name: "r", Include: []string{"("}}},
+		{"invalid exclude", Rule{Name: "r", Include: []string{"error"}, Exclude: []string{"["}}},
+		{"invalid scope", Rule{Name: "r", Include: []string{"error"}, Scope: Scope{Containers: []string{"["}}}},
+		{"invalid field condition", Rule{Name: "r", Fields: []string{"status"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEngine([]Rule{tt.rule}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestImageName(t *testing.T) {
+	tests := map[string]string{
+		"nginx":                          "nginx",
+		"nginx:1.25":                     "nginx",
+		"registry:5000/team/app":         "registry:5000/team/app",
+		"registry:5000/team/app:v2":      "registry:5000/team/app",
+		"app@sha256:0123456789abcdef":    "app",
+		"app:v1@sha256:0123456789abcdef": "app",
+	}
+	for image, want := range tests {
+		if got := imageName(image); got != want {
+			t.Errorf("imageName(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package silence
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/storage"
+)
+
+const silencesFileName = "silences.json"
+
+type Silence struct {
+	ID        string    `json:"id"`
+	Container string    `json:"container"`
+	Pattern   string    `json:"pattern,omitempty"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+func (s *Silence) Forever() bool {
+	return s.ExpiresAt.IsZero()
+}
+
+func (s *Silence) expired(now time.Time) bool {
+	return !s.Forever() && now.After(s.ExpiresAt)
+}
+
+func (s *Silence) matches(container, subject string) bool {
+	if ok, err := path.Match(s.Container, container); err != nil || !ok {
+		return false
+	}
+	return s.pattern == nil || s.pattern.MatchString(subject)
+}
+
+type persistedState struct {
+	NextID   int        `json:"next_id"`
+	Silences []*Silence `json:"silences"`
+}
+
+var (
+	mu       sync.Mutex
+	filePath string
+	state    = persistedState{NextID: 1}
+)
+
+func Init(stateDir string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	filePath = filepath.Join(stateDir, silencesFileName)
+	if err := storage.LoadJSON(filePath, &state); err != nil {
+		return err
+	}
+	var valid []*Silence
+	for _, s := range state.Silences {
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				log.Printf("Dropping silence #%s for %s: invalid pattern: %v", s.ID, s.Container, err)
+				continue
+			}
+			s.pattern = re
+		}
+		valid = append(valid, s)
+	}
+	dropped := len(valid) != len(state.Silences)
+	state.Silences = valid
+	pruneLocked(time.Now())
+	if dropped {
+		return saveLocked()
+	}
+	return nil
+}
+
+func Add(container string, duration time.Duration, pattern, createdBy string) (*Silence, error) {
+	if _, err := path.Match(container, ""); err != nil {
+		return nil, fmt.Errorf("invalid container pattern %q", container)
+	}
+
+	s := &Silence{
+		Container: container,
+		Pattern:   pattern,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %v", err)
+		}
+		s.pattern = re
+	}
+	if duration > 0 {
+		s.ExpiresAt = s.CreatedAt.Add(duration)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	s.ID = strconv.Itoa(state.NextID)
+	state.NextID++
+	state.Silences = append(state.Silences, s)
+	return s, saveLocked()
+}
+
+func Remove(ref string) ([]*Silence, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var removed []*Silence
+	var kept []*Silence
+	for _, s := range state.Silences {
+		if ref == "all" || s.ID == strings.TrimPrefix(ref, "#") || s.Container == ref {
+			removed = append(removed, s)
+			continue
+		}
+		kept = append(kept, s)
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	state.Silences = kept
+	return removed, saveLocked()
+}
+
+func Active() []*Silence {
+	mu.Lock()
+	defer mu.Unlock()
+
+	pruneLocked(time.Now())
+	active := append([]*Silence(nil), state.Silences...)
+	sort.Slice(active, func(i, j int) bool { return active[i].CreatedAt.Before(active[j].CreatedAt) })
+	return active
+}
+
+func IsSilenced(container, subject string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	for _, s := range state.Silences {
+		if !s.expired(now) && s.matches(container, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "forever", "inf", "0":
+		return 0, nil
+	}
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil || days <= 0 || int64(days) > math.MaxInt64/int64(24*time.Hour) {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid duration %q, use e.g. 30m, 1h, 7d or forever", value)
+	}
+	return duration, nil
+}
+
+func pruneLocked(now time.Time) {
+	var kept []*Silence
+	for _, s := range state.Silences {
+		if !s.expired(now) {
+			kept = append(kept, s)
+		}
+	}
+	if len(kept) != len(state.Silences) {
+		state.Silences = kept
+		if err := saveLocked(); err != nil {
+			log.Printf("Error saving silences: %v", err)
+		}
+	}
+}
+
+func saveLocked() error {
+	if filePath == "" {
+		return nil
+	}
+	return storage.SaveJSON(filePath, state)
+}
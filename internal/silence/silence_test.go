@@ -0,0 +1,43 @@
+package silence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30m", 30 * time.Minute, false},
+		{"1h", time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{" 2H ", 2 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"forever", 0, false},
+		{"Forever", 0, false},
+		{"inf", 0, false},
+		{"0", 0, false},
+		{"", 0, true},
+		{"soon", 0, true},
+		{"-1h", 0, true},
+		{"0s", 0, true},
+		{"0d", 0, true},
+		{"-2d", 0, true},
+		{"1.5d", 0, true},
+		{"d", 0, true},
+		{"999999999d", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDuration(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
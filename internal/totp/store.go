@@ -0,0 +1,179 @@
+package totp
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/HarkushaVlad/docker-monitor-bot/internal/storage"
+)
+
+const (
+	enrollmentsFileName = "totp.json"
+
+	maxFailures  = 5
+	lockDuration = 15 * time.Minute
+)
+
+type enrollment struct {
+	Secret      string    `json:"secret"`
+	Confirmed   bool      `json:"confirmed"`
+	Pending     string    `json:"pending,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastStep    int64     `json:"last_step"`
+	Failures    int       `json:"failures,omitempty"`
+	LockedUntil time.Time `json:"locked_until,omitempty"`
+}
+
+type LockoutError struct {
+	Until time.Time
+	New   bool
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("too many invalid codes, try again after %s", e.Until.Format("15:04:05"))
+}
+
+var (
+	mu          sync.Mutex
+	filePath    string
+	enrollments = make(map[int64]*enrollment)
+	sessions    = make(map[int64]time.Time)
+)
+
+func Init(stateDir string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	filePath = filepath.Join(stateDir, enrollmentsFileName)
+	return storage.LoadJSON(filePath, &enrollments)
+}
+
+func Enroll(userID int64, currentCode string) (string, error) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	e, ok := enrollments[userID]
+	if !ok || !e.Confirmed {
+		enrollments[userID] = &enrollment{Secret: secret, CreatedAt: time.Now()}
+		delete(sessions, userID)
+		return secret, saveLocked()
+	}
+
+	if currentCode == "" {
+		return "", fmt.Errorf("two-factor authentication is already enabled, use /2fa setup <current code> to replace it")
+	}
+	now := time.Now()
+	if err := lockedOut(e, now); err != nil {
+		return "", err
+	}
+	counter, valid := match(e.Secret, currentCode, now)
+	if !valid {
+		return "", recordFailureLocked(e, now)
+	}
+	if counter <= e.LastStep {
+		return "", fmt.Errorf("code was already used, wait for the next one")
+	}
+	e.LastStep = counter
+	e.Failures = 0
+	e.Pending = secret
+	return secret, saveLocked()
+}
+
+func Enrolled(userID int64) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	e, ok := enrollments[userID]
+	return ok && e.Confirmed
+}
+
+func Verify(userID int64, code string, sessionLength time.Duration) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	e, ok := enrollments[userID]
+	if !ok {
+		return fmt.Errorf("two-factor authentication is not set up, use /2fa setup")
+	}
+	now := time.Now()
+	if err := lockedOut(e, now); err != nil {
+		return err
+	}
+	counter, valid := match(e.Secret, code, now)
+	if !valid && e.Pending != "" {
+		if counter, valid = match(e.Pending, code, now); valid {
+			e.Secret = e.Pending
+			e.Pending = ""
+			e.LastStep = 0
+		}
+	}
+	if !valid {
+		return recordFailureLocked(e, now)
+	}
+	if counter <= e.LastStep {
+		return fmt.Errorf("code was already used, wait for the next one")
+	}
+
+	e.LastStep = counter
+	e.Failures = 0
+	e.Confirmed = true
+	sessions[userID] = now.Add(sessionLength)
+	return saveLocked()
+}
+
+func Disable(userID int64) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	delete(enrollments, userID)
+	delete(sessions, userID)
+	return saveLocked()
+}
+
+func SessionExpiry(userID int64) (time.Time, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	expiry, ok := sessions[userID]
+	if !ok || time.Now().After(expiry) {
+		delete(sessions, userID)
+		return time.Time{}, false
+	}
+	return expiry, true
+}
+
+func lockedOut(e *enrollment, now time.Time) error {
+	if now.Before(e.LockedUntil) {
+		return &LockoutError{Until: e.LockedUntil}
+	}
+	return nil
+}
+
+func recordFailureLocked(e *enrollment, now time.Time) error {
+	e.Failures++
+	var err error = fmt.Errorf("invalid code, %d attempts left", maxFailures-e.Failures)
+	if e.Failures >= maxFailures {
+		e.Failures = 0
+		e.LockedUntil = now.Add(lockDuration)
+		err = &LockoutError{Until: e.LockedUntil, New: true}
+	}
+	if saveErr := saveLocked(); saveErr != nil {
+		log.Printf("Error saving two-factor enrollments: %v", saveErr)
+	}
+	return err
+}
+
+func saveLocked() error {
+	if filePath == "" {
+		return nil
+	}
+	return storage.SaveJSON(filePath, enrollments)
+}
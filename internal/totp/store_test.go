@@ -0,0 +1,78 @@
+package totp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyLockout(t *testing.T) {
+	if err := Init(t.TempDir()); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	const userID = 42
+	secret, err := Enroll(userID, "")
+	if err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+	key, _ := secretEncoding.DecodeString(secret)
+	wrong := invalidCode(key, time.Now())
+
+	for i := 1; i < maxFailures; i++ {
+		err := Verify(userID, wrong, time.Hour)
+		var lockout *LockoutError
+		if err == nil || errors.As(err, &lockout) {
+			t.Fatalf("attempt %d: got %v, want an invalid code error", i, err)
+		}
+	}
+
+	var lockout *LockoutError
+	if err := Verify(userID, wrong, time.Hour); !errors.As(err, &lockout) || !lockout.New {
+		t.Fatalf("attempt %d: got %v, want a new lockout", maxFailures, err)
+	}
+	if err := Verify(userID, codeAt(key, step(time.Now())), time.Hour); !errors.As(err, &lockout) || lockout.New {
+		t.Fatalf("valid code while locked: got %v, want an existing lockout", err)
+	}
+}
+
+func TestVerifyResetsFailures(t *testing.T) {
+	if err := Init(t.TempDir()); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	const userID = 7
+	secret, _ := Enroll(userID, "")
+	key, _ := secretEncoding.DecodeString(secret)
+	wrong := invalidCode(key, time.Now())
+
+	for i := 1; i < maxFailures; i++ {
+		Verify(userID, wrong, time.Hour)
+	}
+	if err := Verify(userID, codeAt(key, step(time.Now())), time.Hour); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	mu.Lock()
+	enrollments[userID].LastStep = 0
+	mu.Unlock()
+	for i := 1; i < maxFailures; i++ {
+		var lockout *LockoutError
+		if err := Verify(userID, wrong, time.Hour); errors.As(err, &lockout) {
+			t.Fatalf("attempt %d after a valid code: locked out", i)
+		}
+	}
+
+	mu.Lock()
+	enrollments[userID].LockedUntil = time.Now().Add(-time.Second)
+	mu.Unlock()
+	if err := Verify(userID, codeAt(key, step(time.Now())), time.Hour); err != nil {
+		t.Errorf("Verify after the lockout expired: %v", err)
+	}
+}
+
+func invalidCode(key []byte, at time.Time) string {
+	for _, code := range []string{"000000", "111111", "222222"} {
+		if _, ok := match(secretEncoding.EncodeToString(key), code, at); !ok {
+			return code
+		}
+	}
+	return "333333"
+}
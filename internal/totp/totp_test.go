@@ -0,0 +1,54 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B SHA1 vectors, truncated to six digits.
+func TestCodeAtRFC6238(t *testing.T) {
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		if got := codeAt(key, step(time.Unix(tt.unix, 0))); got != tt.want {
+			t.Errorf("codeAt(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	secret := secretEncoding.EncodeToString([]byte("12345678901234567890"))
+	at := time.Unix(1111111111, 0)
+
+	tests := []struct {
+		name string
+		code string
+		ok   bool
+	}{
+		{"current step", "050471", true},
+		{"surrounding spaces", " 050471 ", true},
+		{"previous step", codeAt([]byte("12345678901234567890"), step(at)-1), true},
+		{"next step", codeAt([]byte("12345678901234567890"), step(at)+1), true},
+		{"two steps ago", codeAt([]byte("12345678901234567890"), step(at)-2), false},
+		{"wrong code", "000000", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		if _, ok := match(secret, tt.code, at); ok != tt.ok {
+			t.Errorf("%s: match(%q) = %v, want %v", tt.name, tt.code, ok, tt.ok)
+		}
+	}
+	if _, ok := match("not base32!", "050471", at); ok {
+		t.Error("match accepted an invalid secret")
+	}
+}
@@ -33,3 +33,11 @@ func EscapeHTML(text string) string {
 	)
 	return replacer.Replace(text)
 }
+
+func Truncate(s string, maxRunes int) string {
+	runes := []rune(s)
+	if len(runes) <= maxRunes {
+		return s
+	}
+	return string(runes[:maxRunes]) + "…"
+}